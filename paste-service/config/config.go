@@ -9,11 +9,25 @@ import (
 )
 
 type Config struct {
-	Server   ServerConfig
-	Database DatabaseConfig
-	Cache    CacheConfig
-	Tagger   TaggerConfig
-	SlugGen  SlugGenConfig
+	Server      ServerConfig
+	Database    DatabaseConfig
+	Cache       CacheConfig
+	Tagger      TaggerConfig
+	SlugGen     SlugGenConfig
+	Tracing     TracingConfig
+	Log         LogConfig
+	Health      HealthConfig
+	Admin       AdminConfig
+	Moderation  ModerationConfig
+	Secrets     SecretsConfig
+	Policy      PolicyConfig
+	Security    SecurityConfig
+	Share       ShareConfig
+	Auth        AuthConfig
+	Quota       QuotaConfig
+	Idempotency IdempotencyConfig
+	Expiry      ExpiryConfig
+	TCP         TCPConfig
 }
 
 type ServerConfig struct {
@@ -22,8 +36,20 @@ type ServerConfig struct {
 	WriteTimeout    time.Duration
 	ShutdownTimeout time.Duration
 	MaxRequestSize  int64
-	RateLimit       int
-	TestMode        bool
+	// лимит тела для /api/pastes/upload, где содержимое идет сырым потоком
+	MaxUploadSize int64
+	// запросов в минуту на клиента: RateLimit для чтения, WriteRateLimit для записи; 0 - без ограничения
+	RateLimit      int
+	WriteRateLimit int
+	// где хранить счетчики: memory (у каждой реплики свои) или redis (общие, адрес из CACHE_REDISURL)
+	RateLimitStore string
+	TestMode       bool
+	// внешний адрес сервиса для ссылок в ответах, например https://paste.example.com.
+	// Если пусто, берется из Host запроса
+	PublicURL string
+	// сколько публичную пасту можно отдавать из HTTP-кэша без перепроверки, не дольше ее срока жизни;
+	// 0 - каждый раз перепроверять через If-None-Match
+	CacheMaxAge time.Duration
 }
 
 type DatabaseConfig struct {
@@ -55,6 +81,133 @@ type SlugGenConfig struct {
 	MaxTextSize int
 }
 
+type TracingConfig struct {
+	Enabled     bool
+	Endpoint    string // host:port OTLP/gRPC коллектора
+	Insecure    bool
+	ServiceName string
+	SampleRatio float64
+}
+
+type LogConfig struct {
+	Level  string // debug, info, warn, error
+	Format string // json или text
+}
+
+type HealthConfig struct {
+	Timeout time.Duration
+	// зависимости, падение которых снимает под с трафика: database, redis, tagger, sluggen.
+	// Остальные считаются опциональными и переводят readiness в degraded.
+	Critical []string
+}
+
+func (c HealthConfig) IsCritical(name string) bool {
+	for _, critical := range c.Critical {
+		if strings.EqualFold(critical, name) {
+			return true
+		}
+	}
+	return false
+}
+
+// AdminConfig - учетные данные для /admin (HTTP Basic). Пустой пароль отключает админку.
+type AdminConfig struct {
+	Username string
+	Password string
+}
+
+type ModerationConfig struct {
+	// после скольких жалоб от разных клиентов паста скрывается из листингов, 0 - не скрывать
+	HideThreshold int
+	// сколько жалоб один клиент может отправить за ReportWindow, 0 - без ограничения
+	ReportLimit  int
+	ReportWindow time.Duration
+}
+
+type SecretsConfig struct {
+	Enabled bool
+	// что делать с найденными секретами: reject, redact или private
+	Mode string
+	// минимальная энтропия (бит на символ) для значений вида password=... и Bearer ...
+	EntropyThreshold float64
+}
+
+// PolicyConfig - набор встроенных правил контента. Пустые поля правило отключают.
+type PolicyConfig struct {
+	MaxLines int
+	// регулярные выражения через запятую; выражения с запятыми кладите в DenylistFile
+	Denylist []string
+	// файл с регулярными выражениями, по одному на строку
+	DenylistFile   string
+	BlockedDomains []string
+	RequiredTags   []string
+	ForbiddenTags  []string
+}
+
+type SecurityConfig struct {
+	// серверный секрет для HMAC токенов редактирования; смена перца делает недействительными все токены
+	TokenPepper string
+}
+
+type ShareConfig struct {
+	// ключи подписи ссылок в формате kid:secret через запятую. Первым подписываются
+	// новые ссылки, остальные принимаются для проверки. Пусто - ссылки отключены
+	SigningKeys []string
+	DefaultTTL  time.Duration
+	MaxTTL      time.Duration
+}
+
+type AuthConfig struct {
+	// false закрывает регистрацию новых пользователей, вход остается
+	AllowRegistration bool
+	SessionTTL        time.Duration
+	CookieName        string
+	// ставить cookie только по HTTPS; выключать имеет смысл лишь для локальной разработки
+	CookieSecure bool
+}
+
+// QuotaTier - лимиты одного класса клиентов. 0 - без ограничения.
+type QuotaTier struct {
+	PastesPerDay int64 // паст за скользящие 24 часа
+	StorageBytes int64 // суммарный размер неистекших паст
+	MaxPasteSize int64 // размер одной пасты
+}
+
+type QuotaConfig struct {
+	Enabled   bool
+	Anonymous QuotaTier // по IP
+	User      QuotaTier // по учетной записи
+	APIKey    QuotaTier // по ключу
+	// сколько держать подсчитанное использование в кэше
+	UsageCacheTTL time.Duration
+}
+
+// IdempotencyConfig - хранение ответов на запросы с заголовком Idempotency-Key.
+type IdempotencyConfig struct {
+	// сколько ретрай с тем же ключом получает сохраненный ответ
+	TTL             time.Duration
+	CleanupInterval time.Duration
+}
+
+// TCPConfig - прием паст по голому TCP (`cmd | nc host port`), по умолчанию выключен.
+type TCPConfig struct {
+	Enabled bool
+	Port    string
+	// пауза во вводе, после которой паста считается полученной: nc без -N не закрывает запись
+	IdleTimeout time.Duration
+	// предел на чтение всей пасты, защита от медленных клиентов
+	ReadTimeout    time.Duration
+	MaxConnections int
+}
+
+// ExpiryConfig - политика срока жизни паст. 0 в Max - без ограничения.
+type ExpiryConfig struct {
+	// срок, если клиент его не указал; 0 - бессрочно
+	Default time.Duration
+	Min     time.Duration
+	Max     time.Duration
+}
+
 func DefaultConfig() *Config {
 	return &Config{
 		Server: ServerConfig{
@@ -63,7 +216,10 @@ func DefaultConfig() *Config {
 			WriteTimeout:    10 * time.Second,
 			ShutdownTimeout: 5 * time.Second,
 			MaxRequestSize:  1024 * 1024 * 5,
+			MaxUploadSize:   1024 * 1024 * 32,
 			RateLimit:       100,
+			WriteRateLimit:  20,
+			RateLimitStore:  "memory",
 			TestMode:        false,
 		},
 		Database: DatabaseConfig{
@@ -91,6 +247,78 @@ func DefaultConfig() *Config {
 			Timeout:     5 * time.Second,
 			MaxTextSize: 10000, // 10KB
 		},
+		Tracing: TracingConfig{
+			Enabled:     false,
+			Endpoint:    "localhost:4317",
+			Insecure:    true,
+			ServiceName: "paste-service",
+			SampleRatio: 1.0,
+		},
+		Log: LogConfig{
+			Level:  "info",
+			Format: "json",
+		},
+		Health: HealthConfig{
+			Timeout:  2 * time.Second,
+			Critical: []string{"database", "sluggen"},
+		},
+		Admin: AdminConfig{
+			Username: "admin",
+			Password: "",
+		},
+		Moderation: ModerationConfig{
+			HideThreshold: 3,
+			ReportLimit:   10,
+			ReportWindow:  time.Hour,
+		},
+		Secrets: SecretsConfig{
+			Enabled:          true,
+			Mode:             "private",
+			EntropyThreshold: 3.5,
+		},
+		Share: ShareConfig{
+			DefaultTTL: 24 * time.Hour,
+			MaxTTL:     30 * 24 * time.Hour,
+		},
+		Auth: AuthConfig{
+			AllowRegistration: true,
+			SessionTTL:        30 * 24 * time.Hour,
+			CookieName:        "paste_session",
+			CookieSecure:      true,
+		},
+		Quota: QuotaConfig{
+			Enabled: true,
+			Anonymous: QuotaTier{
+				PastesPerDay: 50,
+				StorageBytes: 20 * 1024 * 1024,
+				MaxPasteSize: 512 * 1024,
+			},
+			User: QuotaTier{
+				PastesPerDay: 500,
+				StorageBytes: 200 * 1024 * 1024,
+				MaxPasteSize: 4 * 1024 * 1024,
+			},
+			APIKey: QuotaTier{
+				PastesPerDay: 5000,
+				StorageBytes: 2 * 1024 * 1024 * 1024,
+				MaxPasteSize: 32 * 1024 * 1024,
+			},
+			UsageCacheTTL: time.Minute,
+		},
+		Idempotency: IdempotencyConfig{
+			TTL:             24 * time.Hour,
+			CleanupInterval: time.Hour,
+		},
+		Expiry: ExpiryConfig{
+			Min: time.Minute,
+		},
+		TCP: TCPConfig{
+			Enabled:        false,
+			Port:           "9999",
+			IdleTimeout:    3 * time.Second,
+			ReadTimeout:    30 * time.Second,
+			MaxConnections: 64,
+		},
 	}
 }
 
@@ -0,0 +1,310 @@
+package api
+
+import (
+	"crypto/sha256"
+	"crypto/subtle"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"time"
+
+	"paste-service/internal/logging"
+	"paste-service/internal/model"
+	"paste-service/internal/service"
+	"paste-service/repository"
+
+	"github.com/gin-gonic/gin"
+)
+
+const adminActorKey = "admin_actor"
+
+func (h *Handler) setupAdminRoutes(r *gin.Engine) {
+	if h.cfg.Admin.Password == "" {
+		slog.Warn("admin password is not set, /admin routes are disabled")
+		return
+	}
+
+	admin := r.Group("/admin", h.adminAuthMiddleware())
+	{
+		admin.GET("/pastes", h.handleAdminListPastes)
+		admin.GET("/pastes/:slug", h.handleAdminGetPaste)
+		admin.DELETE("/pastes/:slug", h.handleAdminDeletePaste)
+		admin.PUT("/pastes/:slug/expiry", h.handleAdminSetExpiry)
+		admin.PUT("/pastes/:slug/lock", h.handleAdminSetLock)
+		admin.POST("/cache/purge", h.handleAdminPurgeCache)
+		admin.GET("/stats", h.handleAdminStats)
+		admin.GET("/audit", h.handleAdminAudit)
+		admin.GET("/reports", h.handleAdminListReports)
+		admin.POST("/reports/:id/resolve", h.handleAdminResolveReport)
+		admin.GET("/keys", h.handleAdminListAPIKeys)
+		admin.POST("/keys", h.handleAdminCreateAPIKey)
+		admin.DELETE("/keys/:id", h.handleAdminRevokeAPIKey)
+	}
+}
+
+// adminAuthMiddleware пускает API-ключ с областью admin или HTTP Basic.
+// Basic сравнивается по хэшам, чтобы время ответа не зависело ни от
+// совпавшего префикса, ни от длины.
+func (h *Handler) adminAuthMiddleware() gin.HandlerFunc {
+	wantUser := sha256.Sum256([]byte(h.cfg.Admin.Username))
+	wantPass := sha256.Sum256([]byte(h.cfg.Admin.Password))
+
+	return func(c *gin.Context) {
+		if principal := currentAPIKey(c); principal != nil {
+			if !principal.HasScope(model.ScopeAdmin) {
+				handleServiceError(c, service.ErrInsufficientScope)
+				c.Abort()
+				return
+			}
+			c.Set(adminActorKey, service.Actor{
+				Name:      "apikey:" + principal.Prefix,
+				RemoteIP:  c.ClientIP(),
+				RequestID: logging.RequestID(c.Request.Context()),
+			})
+			c.Next()
+			return
+		}
+
+		user, pass, ok := c.Request.BasicAuth()
+		gotUser := sha256.Sum256([]byte(user))
+		gotPass := sha256.Sum256([]byte(pass))
+
+		userOK := subtle.ConstantTimeCompare(gotUser[:], wantUser[:]) == 1
+		passOK := subtle.ConstantTimeCompare(gotPass[:], wantPass[:]) == 1
+		if !ok || !userOK || !passOK {
+			slog.WarnContext(c.Request.Context(), "admin authentication failed",
+				slog.String("client_ip", c.ClientIP()),
+			)
+			c.Header("WWW-Authenticate", `Basic realm="admin"`)
+			respondError(c, http.StatusUnauthorized, "admin.unauthorized")
+			return
+		}
+
+		c.Set(adminActorKey, service.Actor{
+			Name:      user,
+			RemoteIP:  c.ClientIP(),
+			RequestID: logging.RequestID(c.Request.Context()),
+		})
+		c.Next()
+	}
+}
+
+func adminActor(c *gin.Context) service.Actor {
+	actor, _ := c.MustGet(adminActorKey).(service.Actor)
+	return actor
+}
+
+func (h *Handler) handleAdminListPastes(c *gin.Context) {
+	filter := repository.PasteFilter{
+		SlugPrefix: c.Query("slug_prefix"),
+		Tag:        c.Query("tag"),
+		Visibility: c.Query("visibility"),
+		Limit:      getQueryIntParam(c, "limit", 50),
+		Offset:     getQueryIntParam(c, "offset", 0),
+	}
+
+	var err error
+	if filter.Expired, err = getQueryBoolPtr(c, "expired"); err != nil {
+		respondInvalidParam(c, "expired")
+		return
+	}
+	if filter.Locked, err = getQueryBoolPtr(c, "locked"); err != nil {
+		respondInvalidParam(c, "locked")
+		return
+	}
+	if filter.Hidden, err = getQueryBoolPtr(c, "hidden"); err != nil {
+		respondInvalidParam(c, "hidden")
+		return
+	}
+	if filter.CreatedAfter, err = getQueryTimePtr(c, "created_after"); err != nil {
+		respondInvalidParam(c, "created_after")
+		return
+	}
+	if filter.CreatedBefore, err = getQueryTimePtr(c, "created_before"); err != nil {
+		respondInvalidParam(c, "created_before")
+		return
+	}
+
+	result, err := h.admin.ListPastes(c.Request.Context(), adminActor(c), filter)
+	if err != nil {
+		handleServiceError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, result)
+}
+
+func (h *Handler) handleAdminGetPaste(c *gin.Context) {
+	paste, err := h.admin.GetPaste(c.Request.Context(), adminActor(c), c.Param("slug"))
+	if err != nil {
+		handleServiceError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, paste)
+}
+
+func (h *Handler) handleAdminDeletePaste(c *gin.Context) {
+	if err := h.admin.DeletePaste(c.Request.Context(), adminActor(c), c.Param("slug")); err != nil {
+		handleServiceError(c, err)
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+type AdminSetExpiryRequest struct {
+	// null снимает ограничение по сроку
+	Expires *time.Time `json:"expires"`
+}
+
+func (h *Handler) handleAdminSetExpiry(c *gin.Context) {
+	var req AdminSetExpiryRequest
+	if !bindJSON(c, &req) {
+		return
+	}
+
+	paste, err := h.admin.SetExpiry(c.Request.Context(), adminActor(c), c.Param("slug"), req.Expires)
+	if err != nil {
+		handleServiceError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, paste)
+}
+
+type AdminSetLockRequest struct {
+	Locked *bool `json:"locked" binding:"required"`
+}
+
+func (h *Handler) handleAdminSetLock(c *gin.Context) {
+	var req AdminSetLockRequest
+	if !bindJSON(c, &req) {
+		return
+	}
+
+	paste, err := h.admin.SetLocked(c.Request.Context(), adminActor(c), c.Param("slug"), *req.Locked)
+	if err != nil {
+		handleServiceError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, paste)
+}
+
+type AdminPurgeCacheRequest struct {
+	Keys []string `json:"keys"`
+	All  bool     `json:"all"`
+}
+
+func (h *Handler) handleAdminPurgeCache(c *gin.Context) {
+	var req AdminPurgeCacheRequest
+	if !bindJSON(c, &req) {
+		return
+	}
+	if !req.All && len(req.Keys) == 0 {
+		respondError(c, http.StatusBadRequest, "admin.purge_target_required")
+		return
+	}
+
+	if err := h.admin.PurgeCache(c.Request.Context(), adminActor(c), req.Keys, req.All); err != nil {
+		handleServiceError(c, err)
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+func (h *Handler) handleAdminStats(c *gin.Context) {
+	stats, err := h.admin.GetStats(c.Request.Context(), adminActor(c))
+	if err != nil {
+		handleServiceError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, stats)
+}
+
+func (h *Handler) handleAdminAudit(c *gin.Context) {
+	entries, err := h.admin.ListAudit(
+		c.Request.Context(),
+		adminActor(c),
+		c.Query("actor"),
+		c.Query("action"),
+		getQueryIntParam(c, "limit", 50),
+		getQueryIntParam(c, "offset", 0),
+	)
+	if err != nil {
+		handleServiceError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, entries)
+}
+
+func (h *Handler) handleAdminListReports(c *gin.Context) {
+	status := c.DefaultQuery("status", model.ReportStatusOpen)
+	if status == "all" {
+		status = ""
+	}
+
+	reports, err := h.admin.ListReports(
+		c.Request.Context(),
+		adminActor(c),
+		status,
+		c.Query("slug"),
+		getQueryIntParam(c, "limit", 50),
+		getQueryIntParam(c, "offset", 0),
+	)
+	if err != nil {
+		handleServiceError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, reports)
+}
+
+type AdminResolveReportRequest struct {
+	Action string `json:"action" binding:"required"`
+}
+
+func (h *Handler) handleAdminResolveReport(c *gin.Context) {
+	var req AdminResolveReportRequest
+	if !bindJSON(c, &req) {
+		return
+	}
+
+	result, err := h.admin.ResolveReport(c.Request.Context(), adminActor(c), c.Param("id"), req.Action)
+	if err != nil {
+		handleServiceError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, result)
+}
+
+func getQueryBoolPtr(c *gin.Context, param string) (*bool, error) {
+	valueStr := c.Query(param)
+	if valueStr == "" {
+		return nil, nil
+	}
+
+	value, err := strconv.ParseBool(valueStr)
+	if err != nil {
+		return nil, err
+	}
+	return &value, nil
+}
+
+func getQueryTimePtr(c *gin.Context, param string) (*time.Time, error) {
+	valueStr := c.Query(param)
+	if valueStr == "" {
+		return nil, nil
+	}
+
+	value, err := time.Parse(time.RFC3339, valueStr)
+	if err != nil {
+		return nil, err
+	}
+	return &value, nil
+}
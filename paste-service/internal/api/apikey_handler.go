@@ -0,0 +1,142 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+
+	"paste-service/internal/service"
+
+	"github.com/gin-gonic/gin"
+)
+
+const apiKeyPrincipalKey = "api_key"
+
+// bearerToken достает ключ из "Authorization: Bearer ...". Basic для админки сюда не попадает.
+func bearerToken(c *gin.Context) (string, bool) {
+	scheme, token, ok := strings.Cut(c.GetHeader("Authorization"), " ")
+	if !ok || !strings.EqualFold(scheme, "Bearer") {
+		return "", false
+	}
+	token = strings.TrimSpace(token)
+	return token, token != ""
+}
+
+// apiKeyMiddleware проверяет API-ключ, если он передан. Неверный ключ - сразу 401:
+// клиент явно представился, и молча пускать его анонимно было бы сюрпризом.
+func (h *Handler) apiKeyMiddleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		raw, ok := bearerToken(c)
+		if !ok {
+			c.Next()
+			return
+		}
+
+		principal, err := h.keys.Authenticate(c.Request.Context(), raw)
+		if err != nil {
+			if errors.Is(err, service.ErrInvalidAPIKey) {
+				c.Header("WWW-Authenticate", `Bearer realm="api"`)
+			}
+			handleServiceError(c, err)
+			c.Abort()
+			return
+		}
+
+		c.Set(apiKeyPrincipalKey, principal)
+		c.Next()
+	}
+}
+
+func currentAPIKey(c *gin.Context) *service.APIKeyPrincipal {
+	if v, ok := c.Get(apiKeyPrincipalKey); ok {
+		if principal, ok := v.(*service.APIKeyPrincipal); ok {
+			return principal
+		}
+	}
+	return nil
+}
+
+// checkKeyScope пропускает запросы без ключа, а запрос с ключом - только при нужной области.
+func checkKeyScope(c *gin.Context, scope string) bool {
+	if principal := currentAPIKey(c); principal != nil && !principal.HasScope(scope) {
+		handleServiceError(c, service.ErrInsufficientScope)
+		return false
+	}
+	return true
+}
+
+func (h *Handler) handleCreateAPIKey(c *gin.Context) {
+	var req service.CreateAPIKeyRequest
+	if !bindJSON(c, &req) {
+		return
+	}
+
+	key, err := h.keys.CreateForUser(c.Request.Context(), currentUser(c), req)
+	if err != nil {
+		handleServiceError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, key)
+}
+
+func (h *Handler) handleListAPIKeys(c *gin.Context) {
+	keys, err := h.keys.ListForUser(c.Request.Context(),
+		currentUserID(c),
+		getQueryIntParam(c, "limit", 50),
+		getQueryIntParam(c, "offset", 0),
+	)
+	if err != nil {
+		handleServiceError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, keys)
+}
+
+func (h *Handler) handleRevokeAPIKey(c *gin.Context) {
+	if err := h.keys.RevokeForUser(c.Request.Context(), currentUserID(c), c.Param("id")); err != nil {
+		handleServiceError(c, err)
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+func (h *Handler) handleAdminCreateAPIKey(c *gin.Context) {
+	var req service.CreateAPIKeyRequest
+	if !bindJSON(c, &req) {
+		return
+	}
+
+	key, err := h.admin.CreateAPIKey(c.Request.Context(), adminActor(c), req)
+	if err != nil {
+		handleServiceError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, key)
+}
+
+func (h *Handler) handleAdminListAPIKeys(c *gin.Context) {
+	keys, err := h.admin.ListAPIKeys(c.Request.Context(), adminActor(c),
+		c.Query("user_id"),
+		getQueryIntParam(c, "limit", 50),
+		getQueryIntParam(c, "offset", 0),
+	)
+	if err != nil {
+		handleServiceError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, keys)
+}
+
+func (h *Handler) handleAdminRevokeAPIKey(c *gin.Context) {
+	if err := h.admin.RevokeAPIKey(c.Request.Context(), adminActor(c), c.Param("id")); err != nil {
+		handleServiceError(c, err)
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
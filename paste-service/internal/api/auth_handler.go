@@ -0,0 +1,241 @@
+package api
+
+import (
+	"errors"
+	"log/slog"
+	"net/http"
+	"time"
+
+	"paste-service/internal/service"
+
+	"github.com/gin-gonic/gin"
+)
+
+const currentUserKey = "current_user"
+
+func (h *Handler) setupAuthRoutes(api *gin.RouterGroup) {
+	auth := api.Group("/auth")
+	{
+		auth.POST("/register", h.handleRegister)
+		auth.POST("/login", h.handleLogin)
+		auth.POST("/logout", h.handleLogout)
+	}
+
+	me := api.Group("/me", requireUser())
+	{
+		me.GET("", h.handleMe)
+		me.GET("/pastes", h.handleMyPastes)
+		me.GET("/keys", h.handleListAPIKeys)
+		me.POST("/keys", h.handleCreateAPIKey)
+		me.DELETE("/keys/:id", h.handleRevokeAPIKey)
+	}
+}
+
+// sessionMiddleware узнает пользователя по cookie сессии. Без cookie или с
+// недействительной сессией запрос идет дальше как анонимный.
+func (h *Handler) sessionMiddleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		token, err := c.Cookie(h.cfg.Auth.CookieName)
+		if err != nil || token == "" {
+			c.Next()
+			return
+		}
+
+		user, err := h.auth.Authenticate(c.Request.Context(), token)
+		switch {
+		case err == nil:
+			c.Set(currentUserKey, user)
+		case errors.Is(err, service.ErrUnauthenticated):
+			h.clearSessionCookie(c)
+		default:
+			slog.ErrorContext(c.Request.Context(), "session lookup failed", slog.Any("error", err))
+		}
+
+		c.Next()
+	}
+}
+
+func requireUser() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if currentUser(c) == nil {
+			respondError(c, http.StatusUnauthorized, "auth.required")
+			return
+		}
+		c.Next()
+	}
+}
+
+func currentUser(c *gin.Context) *service.UserResponse {
+	if v, ok := c.Get(currentUserKey); ok {
+		if user, ok := v.(*service.UserResponse); ok {
+			return user
+		}
+	}
+	return nil
+}
+
+func currentUserID(c *gin.Context) string {
+	if user := currentUser(c); user != nil {
+		return user.ID
+	}
+	return ""
+}
+
+// editorFromRequest собирает права на изменение пасты: сессию и токен,
+// если он не пришел в теле - из заголовка X-Edit-Token.
+func editorFromRequest(c *gin.Context, editToken string) service.Editor {
+	if editToken == "" {
+		editToken = c.GetHeader(editTokenHeader)
+	}
+	return service.Editor{
+		UserID:    currentUserID(c),
+		EditToken: editToken,
+		RemoteIP:  c.ClientIP(),
+	}
+}
+
+func (h *Handler) setSessionCookie(c *gin.Context, session *service.SessionResponse) {
+	http.SetCookie(c.Writer, &http.Cookie{
+		Name:     h.cfg.Auth.CookieName,
+		Value:    session.Token,
+		Path:     "/",
+		Expires:  session.ExpiresAt,
+		MaxAge:   int(time.Until(session.ExpiresAt).Seconds()),
+		Secure:   h.cfg.Auth.CookieSecure,
+		HttpOnly: true,
+		SameSite: http.SameSiteLaxMode,
+	})
+}
+
+func (h *Handler) clearSessionCookie(c *gin.Context) {
+	http.SetCookie(c.Writer, &http.Cookie{
+		Name:     h.cfg.Auth.CookieName,
+		Value:    "",
+		Path:     "/",
+		MaxAge:   -1,
+		Secure:   h.cfg.Auth.CookieSecure,
+		HttpOnly: true,
+		SameSite: http.SameSiteLaxMode,
+	})
+}
+
+type CredentialsRequest struct {
+	Username string `json:"username" binding:"required"`
+	Password string `json:"password" binding:"required"`
+}
+
+func (h *Handler) handleRegister(c *gin.Context) {
+	var req CredentialsRequest
+	if !bindJSON(c, &req) {
+		return
+	}
+
+	session, err := h.auth.Register(c.Request.Context(), req.Username, req.Password)
+	if err != nil {
+		handleServiceError(c, err)
+		return
+	}
+
+	h.setSessionCookie(c, session)
+	c.JSON(http.StatusCreated, session)
+}
+
+func (h *Handler) handleLogin(c *gin.Context) {
+	var req CredentialsRequest
+	if !bindJSON(c, &req) {
+		return
+	}
+
+	session, err := h.auth.Login(c.Request.Context(), req.Username, req.Password)
+	if err != nil {
+		handleServiceError(c, err)
+		return
+	}
+
+	h.setSessionCookie(c, session)
+	c.JSON(http.StatusOK, session)
+}
+
+func (h *Handler) handleLogout(c *gin.Context) {
+	token, _ := c.Cookie(h.cfg.Auth.CookieName)
+	if err := h.auth.Logout(c.Request.Context(), token); err != nil {
+		handleServiceError(c, err)
+		return
+	}
+
+	h.clearSessionCookie(c)
+	c.Status(http.StatusNoContent)
+}
+
+func (h *Handler) handleMe(c *gin.Context) {
+	c.JSON(http.StatusOK, currentUser(c))
+}
+
+func (h *Handler) handleMyPastes(c *gin.Context) {
+	pastes, err := h.service.ListUserPastes(c.Request.Context(),
+		currentUserID(c),
+		getQueryIntParam(c, "limit", 20),
+		getQueryIntParam(c, "offset", 0),
+	)
+	if err != nil {
+		handleServiceError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, pastes)
+}
+
+type ClaimPasteRequest struct {
+	EditToken string `json:"edit_token"`
+}
+
+func (h *Handler) handleClaimPaste(c *gin.Context) {
+	slug := c.Param("slug")
+	if slug == "" {
+		respondError(c, http.StatusBadRequest, "request.slug_required")
+		return
+	}
+
+	var req ClaimPasteRequest
+	if c.Request.ContentLength != 0 {
+		if !bindJSON(c, &req) {
+			return
+		}
+	}
+	if req.EditToken == "" {
+		req.EditToken = c.GetHeader(editTokenHeader)
+	}
+	if req.EditToken == "" {
+		respondError(c, http.StatusBadRequest, "request.edit_token_required")
+		return
+	}
+
+	paste, err := h.service.ClaimPaste(c.Request.Context(), slug, currentUserID(c), req.EditToken)
+	if err != nil {
+		handleServiceError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, paste)
+}
+
+func (h *Handler) handleDeletePaste(c *gin.Context) {
+	slug := c.Param("slug")
+	if slug == "" {
+		respondError(c, http.StatusBadRequest, "request.slug_required")
+		return
+	}
+
+	editor := editorFromRequest(c, "")
+	if editor.UserID == "" && editor.EditToken == "" {
+		respondError(c, http.StatusBadRequest, "request.edit_token_required")
+		return
+	}
+
+	if err := h.service.DeletePaste(c.Request.Context(), slug, editor); err != nil {
+		handleServiceError(c, err)
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
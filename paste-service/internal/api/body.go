@@ -0,0 +1,88 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+	"reflect"
+	"strings"
+
+	"paste-service/internal/i18n"
+
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/validator/v10"
+)
+
+// bodyLimitMiddleware ограничивает тело запроса SERVER_MAXREQUESTSIZE, а загрузку
+// файлов - SERVER_MAXUPLOADSIZE. Заявленный Content-Length проверяется сразу,
+// тело без длины обрывается при чтении.
+func (h *Handler) bodyLimitMiddleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		limit := h.cfg.Server.MaxRequestSize
+		if c.FullPath() == uploadPath {
+			limit = h.cfg.Server.MaxUploadSize
+		}
+		if limit <= 0 || c.Request.Body == nil {
+			c.Next()
+			return
+		}
+
+		if c.Request.ContentLength > limit {
+			respondBodyTooLarge(c, limit)
+			c.Abort()
+			return
+		}
+
+		c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, limit)
+		c.Next()
+	}
+}
+
+func respondBodyTooLarge(c *gin.Context, limit int64) {
+	p := newProblem(c, http.StatusRequestEntityTooLarge, "request.too_large")
+	p.Detail = localize(c, "request.too_large.detail", i18n.Params{"limit": limit})
+	p.Details = gin.H{"limit_bytes": limit}
+	writeProblem(c, p)
+}
+
+// bindJSON разбирает JSON из тела и сам отвечает 400 или 413, если не вышло.
+func bindJSON(c *gin.Context, obj interface{}) bool {
+	err := c.ShouldBindJSON(obj)
+	if err == nil {
+		return true
+	}
+
+	var maxBytesErr *http.MaxBytesError
+	if errors.As(err, &maxBytesErr) {
+		respondBodyTooLarge(c, maxBytesErr.Limit)
+		return false
+	}
+
+	p := newProblem(c, http.StatusBadRequest, "request.invalid")
+	var validationErrs validator.ValidationErrors
+	if errors.As(err, &validationErrs) {
+		for _, fe := range validationErrs {
+			p.addField(c, jsonFieldName(obj, fe.StructField()), "field."+fe.Tag(), nil)
+		}
+	}
+	writeProblem(c, p)
+	return false
+}
+
+// jsonFieldName отдает имя поля так, как его видит клиент в JSON.
+func jsonFieldName(obj interface{}, structField string) string {
+	t := reflect.TypeOf(obj)
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	if t.Kind() != reflect.Struct {
+		return structField
+	}
+	f, ok := t.FieldByName(structField)
+	if !ok {
+		return structField
+	}
+	if name, _, _ := strings.Cut(f.Tag.Get("json"), ","); name != "" && name != "-" {
+		return name
+	}
+	return structField
+}
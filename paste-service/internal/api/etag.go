@@ -0,0 +1,157 @@
+package api
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"paste-service/internal/model"
+	"paste-service/internal/service"
+
+	"github.com/gin-gonic/gin"
+)
+
+// representation - форма, в которой отдается паста. Байты у форм разные, поэтому
+// и ETag у каждой свой: на него опираются Range, If-Range и кэши.
+type representation int
+
+const (
+	reprJSON representation = iota
+	reprText
+	reprHTML
+)
+
+// formatETag строит ETag из версии пасты. Текст отдается сильным "<version>", страница -
+// "<version>-html". JSON - слабый W/"<version>-json": счетчик и время просмотров в нем
+// меняются без новой версии, и побайтно одинаковыми такие ответы не будут.
+func formatETag(version int64, repr representation) string {
+	v := strconv.FormatInt(version, 10)
+	switch repr {
+	case reprText:
+		return `"` + v + `"`
+	case reprHTML:
+		return `"` + v + `-html"`
+	default:
+		return `W/"` + v + `-json"`
+	}
+}
+
+func setETag(c *gin.Context, version int64, repr representation) {
+	c.Header("ETag", formatETag(version, repr))
+}
+
+// parseETag - обратное к formatETag. Признак слабости отбрасывается, представление
+// определяется по суффиксу.
+func parseETag(tag string) (int64, representation, bool) {
+	tag = strings.TrimPrefix(tag, "W/")
+	if len(tag) < 2 || tag[0] != '"' || tag[len(tag)-1] != '"' {
+		return 0, 0, false
+	}
+	tag = tag[1 : len(tag)-1]
+
+	repr := reprText
+	if v, ok := strings.CutSuffix(tag, "-json"); ok {
+		tag, repr = v, reprJSON
+	} else if v, ok := strings.CutSuffix(tag, "-html"); ok {
+		tag, repr = v, reprHTML
+	}
+	version, err := strconv.ParseInt(tag, 10, 64)
+	if err != nil {
+		return 0, 0, false
+	}
+	return version, repr, true
+}
+
+// versionMatch собирает предусловие из If-Match, а без него - из поля version тела.
+// Предусловие сравнивает версию пасты, поэтому подходит ETag любого представления,
+// в том числе слабый ETag JSON: иначе ответом на GET или PUT нельзя было бы
+// воспользоваться для следующей правки.
+func versionMatch(c *gin.Context, bodyVersion *int64) service.VersionMatch {
+	header := strings.TrimSpace(c.GetHeader("If-Match"))
+	if header == "" {
+		if bodyVersion == nil {
+			return service.VersionMatch{}
+		}
+		return service.VersionMatch{Present: true, Versions: []int64{*bodyVersion}}
+	}
+	return parseETags(header, nil)
+}
+
+// readConditions разбирает If-None-Match и If-Modified-Since. Сравнение в If-None-Match
+// слабое, но только с ETag того же представления: иначе кэш получил бы 304 на вариант,
+// которого у него нет. Непонятная дата игнорируется, как того требует RFC 9110.
+// Range и If-Range нужны сервису, чтобы докачка не списывала просмотры ссылки.
+func readConditions(c *gin.Context, repr representation) service.ReadConditions {
+	var cond service.ReadConditions
+	if header := strings.TrimSpace(c.GetHeader("If-None-Match")); header != "" {
+		cond.IfNoneMatch = parseETags(header, &repr)
+	}
+	if v := c.GetHeader("If-Modified-Since"); v != "" {
+		if since, err := http.ParseTime(v); err == nil {
+			cond.IfModifiedSince = &since
+		}
+	}
+
+	// Range понимает только текст: его отдает http.ServeContent
+	if repr == reprText && c.GetHeader("Range") != "" {
+		cond.Range = true
+		switch v := strings.TrimSpace(c.GetHeader("If-Range")); {
+		case v == "":
+		case strings.HasPrefix(v, `"`):
+			cond.IfRange = parseETags(v, &repr)
+		case strings.HasPrefix(v, "W/"):
+			// If-Range требует сильного сравнения, слабый ETag не совпадает никогда
+			cond.IfRange = service.VersionMatch{Present: true}
+		default:
+			if t, err := http.ParseTime(v); err == nil {
+				cond.IfRangeTime = &t
+			} else {
+				cond.IfRange = service.VersionMatch{Present: true}
+			}
+		}
+	}
+	return cond
+}
+
+// parseETags собирает версии из списка ETag. С repr учитываются только ETag этого представления.
+func parseETags(header string, repr *representation) service.VersionMatch {
+	match := service.VersionMatch{Present: true}
+	for _, tag := range strings.Split(header, ",") {
+		tag = strings.TrimSpace(tag)
+		if tag == "*" {
+			match.Any = true
+			continue
+		}
+		version, tagRepr, ok := parseETag(tag)
+		if !ok || (repr != nil && tagRepr != *repr) {
+			continue
+		}
+		match.Versions = append(match.Versions, version)
+	}
+	return match
+}
+
+// setCacheHeaders выставляет валидаторы и Cache-Control для ответа с пастой.
+// Приватная паста зависит от того, кто читает, поэтому в общие кэши не попадает.
+// Публичную можно держать в кэше CacheMaxAge, но не дольше срока жизни пасты.
+func (h *Handler) setCacheHeaders(c *gin.Context, paste *service.PasteResponse, repr representation) {
+	setETag(c, paste.Version, repr)
+	c.Header("Last-Modified", paste.UpdatedAt.UTC().Format(http.TimeFormat))
+
+	if paste.Visibility == model.VisibilityPrivate {
+		c.Header("Cache-Control", "private, no-cache")
+		return
+	}
+
+	maxAge := h.cfg.Server.CacheMaxAge
+	if paste.Expires != nil {
+		maxAge = min(maxAge, time.Until(*paste.Expires))
+	}
+	if maxAge < time.Second {
+		c.Header("Cache-Control", "public, no-cache")
+		return
+	}
+	c.Header("Cache-Control", fmt.Sprintf("public, max-age=%d", int64(maxAge/time.Second)))
+}
@@ -2,38 +2,90 @@ package api
 
 import (
 	"errors"
-	"log"
+	"log/slog"
 	"net/http"
+	"slices"
 	"strconv"
+	"strings"
 	"time"
 
+	"paste-service/config"
+	"paste-service/internal/expiry"
+	"paste-service/internal/health"
+	"paste-service/internal/i18n"
+	"paste-service/internal/model"
+	"paste-service/internal/policy"
+	"paste-service/internal/ratelimit"
 	"paste-service/internal/service"
 
 	"github.com/gin-gonic/gin"
+	"go.opentelemetry.io/contrib/instrumentation/github.com/gin-gonic/gin/otelgin"
 )
 
+// editTokenHeader - заголовок с токеном редактирования для чтения приватных паст.
+// В query токен не принимаем, чтобы он не оседал в логах прокси.
+const editTokenHeader = "X-Edit-Token"
+
+// shareTokenHeader - альтернатива параметру ?share= для подписанных ссылок.
+const shareTokenHeader = "X-Share-Token"
+
 type Handler struct {
-	service *service.PasteService
-	router  *gin.Engine
+	service     *service.PasteService
+	reports     *service.ReportService
+	admin       *service.AdminService
+	auth        *service.AuthService
+	keys        *service.APIKeyService
+	idempotency *service.IdempotencyService
+	limiter     ratelimit.Store
+	health      *health.Checker
+	router      *gin.Engine
+	cfg         *config.Config
 }
 
-func NewHandler(service *service.PasteService) *Handler {
+func NewHandler(
+	service *service.PasteService,
+	reports *service.ReportService,
+	admin *service.AdminService,
+	auth *service.AuthService,
+	keys *service.APIKeyService,
+	idempotency *service.IdempotencyService,
+	limiter ratelimit.Store,
+	health *health.Checker,
+	cfg *config.Config,
+) *Handler {
 	h := &Handler{
-		service: service,
+		service:     service,
+		reports:     reports,
+		admin:       admin,
+		auth:        auth,
+		keys:        keys,
+		idempotency: idempotency,
+		limiter:     limiter,
+		health:      health,
+		cfg:         cfg,
 	}
 	h.setupRouter()
 	return h
 }
 
 func (h *Handler) setupRouter() {
-	r := gin.Default()
+	r := gin.New()
+
+	// спан на каждый запрос, входящий traceparent подхватывается из заголовков
+	r.Use(otelgin.Middleware(h.cfg.Tracing.ServiceName))
+	r.Use(requestIDMiddleware())
+	r.Use(accessLogMiddleware())
+	r.Use(recoveryMiddleware())
+	r.Use(h.sessionMiddleware())
+	r.Use(h.apiKeyMiddleware())
+	r.Use(h.bodyLimitMiddleware())
 
 	// CORS middleware
 	r.Use(func(c *gin.Context) {
 		c.Writer.Header().Set("Access-Control-Allow-Origin", "*")
-		c.Writer.Header().Set("Access-Control-Allow-Methods", "GET, POST, PUT, DELETE, OPTIONS")
-		c.Writer.Header().Set("Access-Control-Allow-Headers", "Accept, Authorization, Content-Type")
-		c.Writer.Header().Set("Access-Control-Expose-Headers", "Link")
+		c.Writer.Header().Set("Access-Control-Allow-Methods", "GET, POST, PUT, PATCH, DELETE, OPTIONS")
+		c.Writer.Header().Set("Access-Control-Allow-Headers", "Accept, Authorization, Content-Type, X-Edit-Token, X-Share-Token, Idempotency-Key, If-Match, If-None-Match, If-Modified-Since, X-Request-ID, traceparent, tracestate")
+		c.Writer.Header().Set("Access-Control-Expose-Headers", "Link, ETag, Last-Modified, X-Request-ID, Idempotency-Replayed, RateLimit-Policy, RateLimit-Limit, RateLimit-Remaining, RateLimit-Reset, Retry-After")
 		c.Writer.Header().Set("Access-Control-Allow-Credentials", "true")
 		c.Writer.Header().Set("Access-Control-Max-Age", "300")
 
@@ -45,23 +97,52 @@ func (h *Handler) setupRouter() {
 		c.Next()
 	})
 
-	// TODO: rate limit
-
 	r.GET("/", h.handleHome)
 	r.GET("/health", h.handleHealth)
+	r.GET("/health/live", h.handleLiveness)
+	r.GET("/health/ready", h.handleReadiness)
 
-	api := r.Group("/api")
+	api := r.Group("/api", h.rateLimitMiddleware())
 	{
+		h.setupAuthRoutes(api)
+		api.GET("/quota", h.handleGetQuota)
+
 		pastes := api.Group("/pastes")
 		{
-			pastes.POST("/", h.handleCreatePaste)
+			// без слэша тоже: curl не повторяет POST после редиректа
+			pastes.POST("", h.idempotencyMiddleware(), h.handleCreatePaste)
+			pastes.POST("/", h.idempotencyMiddleware(), h.handleCreatePaste)
+			pastes.POST("/upload", h.handleUploadPaste)
+			pastes.PUT("/upload", h.handleUploadPaste)
 			pastes.GET("/top", h.handleGetTopPastes)
 			pastes.GET("/recent", h.handleGetRecentPastes)
 			pastes.GET("/:slug", h.handleGetPaste)
+			pastes.GET("/:slug/raw", h.handleGetRaw)
+			pastes.GET("/:slug/download", h.handleDownload)
 			pastes.PUT("/:slug", h.handleUpdatePaste)
+			pastes.PATCH("/:slug", h.handlePatchPaste)
+			pastes.DELETE("/:slug", h.handleDeletePaste)
+			pastes.POST("/:slug/claim", requireUser(), h.handleClaimPaste)
+			pastes.POST("/:slug/report", h.handleReportPaste)
+			pastes.PUT("/:slug/expiry", h.handleSetExpiry)
+			pastes.POST("/:slug/rotate-token", h.handleRotateToken)
+			pastes.POST("/:slug/share", h.handleCreateShareLink)
+			pastes.GET("/:slug/share", h.handleListShareLinks)
+			pastes.DELETE("/:slug/share/:id", h.handleRevokeShareLink)
 		}
 	}
 
+	// короткая ссылка host/<slug> для браузера и curl. Статические маршруты выше
+	// (/health, /api, /admin) у gin важнее параметра, так что их не перекрывает
+	short := r.Group("", h.rateLimitMiddleware())
+	{
+		short.GET("/:slug", h.handleGetPaste)
+		short.GET("/:slug/raw", h.handleGetRaw)
+		short.GET("/:slug/download", h.handleDownload)
+	}
+
+	h.setupAdminRoutes(r)
+
 	h.router = r
 }
 
@@ -82,84 +163,326 @@ func (h *Handler) handleHealth(c *gin.Context) {
 	})
 }
 
+// handleLiveness отвечает, пока процесс жив и обрабатывает запросы.
+// Зависимости здесь не проверяются, иначе падение БД приведет к рестарту всех подов.
+func (h *Handler) handleLiveness(c *gin.Context) {
+	c.JSON(http.StatusOK, gin.H{
+		"status": health.StatusOK,
+	})
+}
+
+func (h *Handler) handleReadiness(c *gin.Context) {
+	report := h.health.Check(c.Request.Context())
+
+	status := http.StatusOK
+	if !report.Ready() {
+		status = http.StatusServiceUnavailable
+	}
+
+	c.JSON(status, report)
+}
+
 type CreatePasteRequest struct {
-	Content   string         `json:"content" binding:"required"`
-	Tags      []string       `json:"tags,omitempty"`
-	ExpiresIn *time.Duration `json:"expires_in,omitempty"`
-	AutoTag   bool           `json:"auto_tag"`
+	Content     string       `json:"content" binding:"required"`
+	Tags        []string     `json:"tags,omitempty"`
+	ExpiresIn   expiry.Input `json:"expires_in,omitempty"`
+	ExpiresAt   *time.Time   `json:"expires_at,omitempty"`
+	AutoTag     bool         `json:"auto_tag"`
+	Visibility  string       `json:"visibility,omitempty"`
+	Title       string       `json:"title,omitempty"`
+	Description string       `json:"description,omitempty"`
+	Filename    string       `json:"filename,omitempty"`
+	Language    string       `json:"language,omitempty"`
 }
 
 func (h *Handler) handleCreatePaste(c *gin.Context) {
+	if !checkKeyScope(c, model.ScopePasteCreate) {
+		return
+	}
+
+	if isPlainBody(c) {
+		h.handleCreatePlain(c)
+		return
+	}
+
 	var req CreatePasteRequest
-	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "Некорректный запрос"})
+	if !bindJSON(c, &req) {
 		return
 	}
 
 	serviceReq := service.CreatePasteRequest{
-		Content:   req.Content,
-		Tags:      req.Tags,
-		ExpiresIn: req.ExpiresIn,
-		AutoTag:   req.AutoTag,
+		Content:     req.Content,
+		Tags:        req.Tags,
+		ExpiresIn:   string(req.ExpiresIn),
+		ExpiresAt:   req.ExpiresAt,
+		AutoTag:     req.AutoTag,
+		Visibility:  req.Visibility,
+		Title:       req.Title,
+		Description: req.Description,
+		Filename:    req.Filename,
+		Language:    req.Language,
 	}
+	setCreator(c, &serviceReq)
 
-	paste, err := h.service.CreatePaste(serviceReq)
+	paste, err := h.service.CreatePaste(c.Request.Context(), serviceReq)
 	if err != nil {
 		handleServiceError(c, err)
 		return
 	}
 
+	localizeWarnings(c, paste.Warnings)
+	setETag(c, paste.Version, reprJSON)
 	c.JSON(http.StatusCreated, paste)
 }
 
+// setCreator приписывает новую пасту клиенту: ключу и его владельцу,
+// пользователю из сессии или IP для квот.
+func setCreator(c *gin.Context, req *service.CreatePasteRequest) {
+	req.OwnerID = currentUserID(c)
+	req.ClientIP = c.ClientIP()
+	if key := currentAPIKey(c); key != nil {
+		req.APIKeyID = key.ID
+		if req.OwnerID == "" {
+			req.OwnerID = key.UserID
+		}
+	}
+}
+
+func (h *Handler) handleGetQuota(c *gin.Context) {
+	subject := service.QuotaSubject{
+		UserID:   currentUserID(c),
+		ClientIP: c.ClientIP(),
+	}
+	if key := currentAPIKey(c); key != nil {
+		subject.APIKeyID = key.ID
+	}
+
+	usage, err := h.service.GetQuotaUsage(c.Request.Context(), subject)
+	if err != nil {
+		handleServiceError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, usage)
+}
+
 func (h *Handler) handleGetPaste(c *gin.Context) {
+	// один адрес - три представления, кэши должны различать их по Accept
+	c.Header("Vary", "Accept")
+	format := c.NegotiateFormat(gin.MIMEJSON, gin.MIMEPlain, gin.MIMEHTML)
+	if format == "" {
+		respondError(c, http.StatusNotAcceptable, "request.not_acceptable")
+		return
+	}
+
+	repr := reprJSON
+	switch format {
+	case gin.MIMEPlain:
+		repr = reprText
+	case gin.MIMEHTML:
+		repr = reprHTML
+	}
+
+	paste, ok := h.readPaste(c, repr)
+	if !ok {
+		return
+	}
+
+	switch repr {
+	case reprText:
+		serveRaw(c, paste)
+	case reprHTML:
+		servePastePage(c, paste)
+	default:
+		c.JSON(http.StatusOK, paste)
+	}
+}
+
+// readPaste читает пасту с учетом доступа и условных заголовков для представления repr.
+// Если ответ уже отправлен (ошибка или 304), возвращает false.
+func (h *Handler) readPaste(c *gin.Context, repr representation) (*service.PasteResponse, bool) {
 	slug := c.Param("slug")
 	if slug == "" {
-		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "Не указан slug"})
-		return
+		respondError(c, http.StatusBadRequest, "request.slug_required")
+		return nil, false
 	}
 
-	paste, err := h.service.GetPaste(slug)
+	paste, notModified, err := h.service.GetPaste(c.Request.Context(), slug, readAccess(c), readConditions(c, repr))
 	if err != nil {
 		handleServiceError(c, err)
-		return
+		return nil, false
 	}
 
-	c.JSON(http.StatusOK, paste)
+	h.setCacheHeaders(c, paste, repr)
+	if notModified {
+		c.Status(http.StatusNotModified)
+		return nil, false
+	}
+	return paste, true
+}
+
+func readAccess(c *gin.Context) service.ReadAccess {
+	access := service.ReadAccess{
+		UserID:     currentUserID(c),
+		EditToken:  c.GetHeader(editTokenHeader),
+		ShareToken: c.Query("share"),
+	}
+	if access.ShareToken == "" {
+		access.ShareToken = c.GetHeader(shareTokenHeader)
+	}
+	if key := currentAPIKey(c); key != nil && access.UserID == "" && key.HasScope(model.ScopePasteReadPrivate) {
+		access.UserID = key.UserID
+	}
+	return access
 }
 
 type UpdatePasteRequest struct {
 	Content   string   `json:"content" binding:"required"`
 	Tags      []string `json:"tags,omitempty"`
-	EditToken string   `json:"edit_token" binding:"required"`
+	EditToken string   `json:"edit_token"`        // владельцу можно не передавать
+	Version   *int64   `json:"version,omitempty"` // альтернатива If-Match
+	// отсутствующие поля метаданных не меняются
+	Title       *string `json:"title,omitempty"`
+	Description *string `json:"description,omitempty"`
+	Filename    *string `json:"filename,omitempty"`
+	Language    *string `json:"language,omitempty"`
 }
 
 func (h *Handler) handleUpdatePaste(c *gin.Context) {
 	slug := c.Param("slug")
 	if slug == "" {
-		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "Не указан slug"})
+		respondError(c, http.StatusBadRequest, "request.slug_required")
 		return
 	}
 
 	var req UpdatePasteRequest
-	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "Некорректный запрос"})
+	if !bindJSON(c, &req) {
+		return
+	}
+
+	editor := editorFromRequest(c, req.EditToken)
+	if editor.UserID == "" && editor.EditToken == "" {
+		respondError(c, http.StatusBadRequest, "request.edit_token_required")
 		return
 	}
 
-	paste, err := h.service.UpdatePaste(slug, req.EditToken, req.Content, req.Tags)
+	paste, err := h.service.UpdatePaste(c.Request.Context(), slug, editor, versionMatch(c, req.Version), req.Content, req.Tags, service.PasteMetadata{
+		Title:       req.Title,
+		Description: req.Description,
+		Filename:    req.Filename,
+		Language:    req.Language,
+	})
 	if err != nil {
 		handleServiceError(c, err)
 		return
 	}
 
+	localizeWarnings(c, paste.Warnings)
+	setETag(c, paste.Version, reprJSON)
 	c.JSON(http.StatusOK, paste)
 }
 
+type SetExpiryRequest struct {
+	ExpiresIn expiry.Input `json:"expires_in,omitempty"`
+	ExpiresAt *time.Time   `json:"expires_at,omitempty"`
+	EditToken string       `json:"edit_token"`
+	Version   *int64       `json:"version,omitempty"` // альтернатива If-Match
+}
+
+func (h *Handler) handleSetExpiry(c *gin.Context) {
+	slug := c.Param("slug")
+	if slug == "" {
+		respondError(c, http.StatusBadRequest, "request.slug_required")
+		return
+	}
+
+	var req SetExpiryRequest
+	if !bindJSON(c, &req) {
+		return
+	}
+
+	editor := editorFromRequest(c, req.EditToken)
+	if editor.UserID == "" && editor.EditToken == "" {
+		respondError(c, http.StatusBadRequest, "request.edit_token_required")
+		return
+	}
+
+	paste, err := h.service.SetExpiry(c.Request.Context(), slug, editor, versionMatch(c, req.Version), string(req.ExpiresIn), req.ExpiresAt)
+	if err != nil {
+		handleServiceError(c, err)
+		return
+	}
+
+	setETag(c, paste.Version, reprJSON)
+	c.JSON(http.StatusOK, paste)
+}
+
+type RotateTokenRequest struct {
+	EditToken string `json:"edit_token"`
+}
+
+func (h *Handler) handleRotateToken(c *gin.Context) {
+	slug := c.Param("slug")
+	if slug == "" {
+		respondError(c, http.StatusBadRequest, "request.slug_required")
+		return
+	}
+
+	// токен можно передать в теле или в заголовке X-Edit-Token
+	var req RotateTokenRequest
+	if c.Request.ContentLength != 0 {
+		if !bindJSON(c, &req) {
+			return
+		}
+	}
+	editor := editorFromRequest(c, req.EditToken)
+	if editor.UserID == "" && editor.EditToken == "" {
+		respondError(c, http.StatusBadRequest, "request.edit_token_required")
+		return
+	}
+
+	resp, err := h.service.RotateEditToken(c.Request.Context(), slug, editor)
+	if err != nil {
+		handleServiceError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, resp)
+}
+
+func (h *Handler) handleReportPaste(c *gin.Context) {
+	slug := c.Param("slug")
+	if slug == "" {
+		respondError(c, http.StatusBadRequest, "request.slug_required")
+		return
+	}
+
+	var req service.CreateReportRequest
+	if !bindJSON(c, &req) {
+		return
+	}
+	if req.Reason == "" {
+		respondError(c, http.StatusBadRequest, "request.invalid")
+		return
+	}
+
+	report, err := h.reports.CreateReport(c.Request.Context(), slug, c.ClientIP(), readAccess(c), req)
+	if err != nil {
+		handleServiceError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, report)
+}
+
 func (h *Handler) handleGetTopPastes(c *gin.Context) {
 	limit := getQueryIntParam(c, "limit", 10)
+	fields, ok := listFields(c)
+	if !ok {
+		return
+	}
 
-	pastes, err := h.service.GetTopPastes(limit)
+	pastes, err := h.service.GetTopPastes(c.Request.Context(), limit, fields)
 	if err != nil {
 		handleServiceError(c, err)
 		return
@@ -170,8 +493,12 @@ func (h *Handler) handleGetTopPastes(c *gin.Context) {
 
 func (h *Handler) handleGetRecentPastes(c *gin.Context) {
 	limit := getQueryIntParam(c, "limit", 10)
+	fields, ok := listFields(c)
+	if !ok {
+		return
+	}
 
-	pastes, err := h.service.GetRecentPastes(limit)
+	pastes, err := h.service.GetRecentPastes(c.Request.Context(), limit, fields)
 	if err != nil {
 		handleServiceError(c, err)
 		return
@@ -180,27 +507,127 @@ func (h *Handler) handleGetRecentPastes(c *gin.Context) {
 	c.JSON(http.StatusOK, pastes)
 }
 
-type ErrorResponse struct {
-	Error string `json:"error"`
+// listFields разбирает ?fields=content,expires. Неизвестное поле - 400, а не тихий пропуск,
+// чтобы опечатка не выглядела как пустое значение.
+func listFields(c *gin.Context) ([]string, bool) {
+	raw := c.Query("fields")
+	if raw == "" {
+		return nil, true
+	}
+	var fields []string
+	for _, field := range strings.Split(raw, ",") {
+		field = strings.TrimSpace(field)
+		if field == "" || slices.Contains(fields, field) {
+			continue
+		}
+		if !slices.Contains(service.SummaryFields, field) {
+			respondInvalidParam(c, "fields")
+			return nil, false
+		}
+		fields = append(fields, field)
+	}
+	return fields, true
 }
 
 func handleServiceError(c *gin.Context, err error) {
+	var (
+		secretsErr *service.SecretsDetectedError
+		violation  *policy.Violation
+		quotaErr   *service.QuotaExceededError
+	)
+
 	switch {
+	case errors.As(err, &quotaErr):
+		status := http.StatusTooManyRequests
+		switch quotaErr.Quota {
+		case service.QuotaPasteSize:
+			status = http.StatusRequestEntityTooLarge
+		case service.QuotaStorageBytes:
+			status = http.StatusInsufficientStorage
+		}
+		code := "quota." + quotaErr.Quota
+		p := newProblem(c, status, code)
+		p.Title = localize(c, "quota.exceeded", nil)
+		p.Error = p.Title
+		p.Detail = localize(c, code, i18n.Params{"limit": quotaErr.Limit, "used": quotaErr.Used})
+		p.Details = quotaErr
+		writeProblem(c, p)
+	case errors.As(err, &violation):
+		p := newProblem(c, http.StatusUnprocessableEntity, violation.Code)
+		p.Title = localize(c, "policy.violation", nil)
+		p.Error = p.Title
+		p.Detail = localize(c, violation.Code, violation.Params)
+		p.Details = violation
+		writeProblem(c, p)
+	case errors.As(err, &secretsErr):
+		p := newProblem(c, http.StatusUnprocessableEntity, "paste.secrets_detected")
+		p.Details = secretsErr.Findings
+		writeProblem(c, p)
+	case errors.Is(err, model.ErrContentTooLarge):
+		respondValidationError(c, http.StatusRequestEntityTooLarge, "paste.too_large", err)
 	case errors.Is(err, service.ErrInvalidPaste):
-		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "Некорректные данные пасты"})
+		respondValidationError(c, http.StatusBadRequest, "paste.invalid", err)
 	case errors.Is(err, service.ErrPasteNotFound):
-		c.JSON(http.StatusNotFound, ErrorResponse{Error: "Паста не найдена"})
+		respondError(c, http.StatusNotFound, "paste.not_found")
 	case errors.Is(err, service.ErrInvalidEditToken):
-		c.JSON(http.StatusForbidden, ErrorResponse{Error: "Неверный токен редактирования"})
+		respondError(c, http.StatusForbidden, "paste.invalid_edit_token")
 	case errors.Is(err, service.ErrPasteExpired):
-		c.JSON(http.StatusGone, ErrorResponse{Error: "Срок действия пасты истек"})
+		respondError(c, http.StatusGone, "paste.expired")
+	case errors.Is(err, service.ErrPasteLocked):
+		respondError(c, http.StatusLocked, "paste.locked")
+	case errors.Is(err, service.ErrPreconditionRequired):
+		respondError(c, http.StatusPreconditionRequired, "paste.precondition_required")
+	case errors.Is(err, service.ErrVersionMismatch):
+		respondError(c, http.StatusPreconditionFailed, "paste.version_mismatch")
+	case errors.Is(err, service.ErrInvalidReport):
+		respondValidationError(c, http.StatusBadRequest, "report.invalid", err)
+	case errors.Is(err, service.ErrReportNotFound):
+		respondError(c, http.StatusNotFound, "report.not_found")
+	case errors.Is(err, service.ErrAlreadyReported):
+		respondError(c, http.StatusConflict, "report.duplicate")
+	case errors.Is(err, service.ErrReportLimitExceeded):
+		respondError(c, http.StatusTooManyRequests, "report.rate_limited")
+	case errors.Is(err, service.ErrInvalidShareLink):
+		respondValidationError(c, http.StatusBadRequest, "share.invalid", err)
+	case errors.Is(err, service.ErrShareLinkNotFound):
+		respondError(c, http.StatusNotFound, "share.not_found")
+	case errors.Is(err, service.ErrShareLinkExhausted):
+		respondError(c, http.StatusForbidden, "share.exhausted")
+	case errors.Is(err, service.ErrSharingDisabled):
+		respondError(c, http.StatusServiceUnavailable, "share.disabled")
+	case errors.Is(err, service.ErrInvalidAccount):
+		respondValidationError(c, http.StatusBadRequest, "account.invalid", err)
+	case errors.Is(err, service.ErrUsernameTaken):
+		respondError(c, http.StatusConflict, "account.username_taken")
+	case errors.Is(err, service.ErrInvalidCredentials):
+		respondError(c, http.StatusUnauthorized, "account.invalid_credentials")
+	case errors.Is(err, service.ErrUnauthenticated):
+		respondError(c, http.StatusUnauthorized, "auth.required")
+	case errors.Is(err, service.ErrRegistrationDisabled):
+		respondError(c, http.StatusForbidden, "account.registration_closed")
+	case errors.Is(err, service.ErrPasteAlreadyOwned):
+		respondError(c, http.StatusConflict, "paste.already_owned")
+	case errors.Is(err, service.ErrInvalidAPIKey):
+		respondError(c, http.StatusUnauthorized, "apikey.invalid")
+	case errors.Is(err, service.ErrInsufficientScope):
+		respondError(c, http.StatusForbidden, "apikey.insufficient_scope")
+	case errors.Is(err, service.ErrInvalidAPIKeyRequest):
+		respondValidationError(c, http.StatusBadRequest, "apikey.invalid_request", err)
+	case errors.Is(err, service.ErrAPIKeyNotFound):
+		respondError(c, http.StatusNotFound, "apikey.not_found")
+	case errors.Is(err, service.ErrInvalidIdempotencyKey):
+		respondError(c, http.StatusBadRequest, "idempotency.invalid_key")
+	case errors.Is(err, service.ErrIdempotencyKeyReused):
+		respondError(c, http.StatusUnprocessableEntity, "idempotency.key_reused")
+	case errors.Is(err, service.ErrIdempotencyInProgress):
+		respondError(c, http.StatusConflict, "idempotency.in_progress")
 	case errors.Is(err, service.ErrTaggerUnavailable):
-		c.JSON(http.StatusServiceUnavailable, ErrorResponse{Error: "Сервис тэггирования недоступен"})
+		respondError(c, http.StatusServiceUnavailable, "tagger.unavailable")
 	case errors.Is(err, service.ErrSlugGeneratorUnavailable):
-		c.JSON(http.StatusServiceUnavailable, ErrorResponse{Error: "Сервис генерации slug недоступен"})
+		respondError(c, http.StatusServiceUnavailable, "sluggen.unavailable")
 	default:
-		log.Printf("Необработанная ошибка: %v", err)
-		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: "Внутренняя ошибка сервера"})
+		slog.ErrorContext(c.Request.Context(), "unhandled service error", slog.Any("error", err))
+		respondError(c, http.StatusInternalServerError, "internal")
 	}
 }
 
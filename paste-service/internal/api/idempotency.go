@@ -0,0 +1,130 @@
+package api
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"errors"
+	"io"
+	"log/slog"
+	"net/http"
+
+	"paste-service/internal/service"
+
+	"github.com/gin-gonic/gin"
+)
+
+const idempotencyKeyHeader = "Idempotency-Key"
+
+// replayedHeaders сохраняются вместе с ответом: без них ретрай не узнал бы
+// версию созданной пасты и ее адрес.
+var replayedHeaders = []string{"ETag", "Location"}
+
+// responseRecorder копирует ответ обработчика, чтобы сохранить его для ретраев.
+type responseRecorder struct {
+	gin.ResponseWriter
+	body bytes.Buffer
+}
+
+func (w *responseRecorder) Write(data []byte) (int, error) {
+	w.body.Write(data)
+	return w.ResponseWriter.Write(data)
+}
+
+func (w *responseRecorder) WriteString(s string) (int, error) {
+	w.body.WriteString(s)
+	return w.ResponseWriter.WriteString(s)
+}
+
+// idempotencyMiddleware делает запрос с заголовком Idempotency-Key безопасным для
+// повтора: успешный ответ сохраняется и отдается ретраям с тем же ключом и телом.
+// Неуспешный ответ не сохраняется, ретрай выполнится заново.
+func (h *Handler) idempotencyMiddleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		key := c.GetHeader(idempotencyKeyHeader)
+		if key == "" || h.idempotency == nil {
+			c.Next()
+			return
+		}
+
+		body, err := io.ReadAll(c.Request.Body)
+		if err != nil {
+			var maxBytesErr *http.MaxBytesError
+			if errors.As(err, &maxBytesErr) {
+				respondBodyTooLarge(c, maxBytesErr.Limit)
+			} else {
+				respondError(c, http.StatusBadRequest, "request.invalid")
+			}
+			c.Abort()
+			return
+		}
+		c.Request.Body = io.NopCloser(bytes.NewReader(body))
+
+		ctx := c.Request.Context()
+		scope := idempotencyScope(c)
+		hash := sha256.New()
+		hash.Write([]byte(c.Request.Method + " " + c.FullPath() + "\n"))
+		// у текстового создания параметры пасты в query и заголовках, а не в теле
+		if query := c.Request.URL.RawQuery; query != "" {
+			hash.Write([]byte("?" + query + "\n"))
+		}
+		for _, p := range uploadParamHeaders {
+			if v := c.GetHeader(p.header); v != "" {
+				hash.Write([]byte(p.header + ": " + v + "\n"))
+			}
+		}
+		hash.Write(body)
+
+		stored, err := h.idempotency.Begin(ctx, scope, key, hash.Sum(nil))
+		if err != nil {
+			handleServiceError(c, err)
+			c.Abort()
+			return
+		}
+		if stored != nil {
+			for name, value := range stored.Headers {
+				c.Header(name, value)
+			}
+			c.Header("Idempotency-Replayed", "true")
+			c.Data(stored.Status, stored.ContentType, stored.Body)
+			c.Abort()
+			return
+		}
+
+		recorder := &responseRecorder{ResponseWriter: c.Writer}
+		c.Writer = recorder
+		c.Next()
+
+		status := recorder.Status()
+		if status >= 200 && status < 300 {
+			headers := make(map[string]string)
+			for _, name := range replayedHeaders {
+				if v := recorder.Header().Get(name); v != "" {
+					headers[name] = v
+				}
+			}
+			err = h.idempotency.Complete(ctx, scope, key, service.StoredResponse{
+				Status:      status,
+				ContentType: recorder.Header().Get("Content-Type"),
+				Headers:     headers,
+				Body:        recorder.body.Bytes(),
+			})
+		} else {
+			err = h.idempotency.Abort(ctx, scope, key)
+		}
+		if err != nil {
+			// ответ клиенту уже отправлен, ретрай получит 409 до истечения pending-записи
+			slog.ErrorContext(ctx, "idempotency record update failed", slog.Any("error", err))
+		}
+	}
+}
+
+// idempotencyScope - владелец ключа: одинаковые ключи разных клиентов не пересекаются.
+func idempotencyScope(c *gin.Context) string {
+	if key := currentAPIKey(c); key != nil {
+		return "key:" + key.ID
+	}
+	if userID := currentUserID(c); userID != "" {
+		return "user:" + userID
+	}
+	return "ip:" + c.ClientIP()
+}
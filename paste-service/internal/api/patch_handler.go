@@ -0,0 +1,173 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"sort"
+	"time"
+
+	"paste-service/internal/expiry"
+	"paste-service/internal/service"
+
+	"github.com/gin-gonic/gin"
+)
+
+const mergePatchContentType = "application/merge-patch+json"
+
+// handlePatchPaste меняет пасту по JSON Merge Patch (RFC 7396): отсутствующее поле
+// не меняется, null удаляет значение там, где это имеет смысл.
+func (h *Handler) handlePatchPaste(c *gin.Context) {
+	slug := c.Param("slug")
+	if slug == "" {
+		respondError(c, http.StatusBadRequest, "request.slug_required")
+		return
+	}
+
+	if ct := c.ContentType(); ct != mergePatchContentType && ct != "application/json" {
+		c.Header("Accept-Patch", mergePatchContentType)
+		respondError(c, http.StatusUnsupportedMediaType, "request.unsupported_media_type")
+		return
+	}
+
+	body, err := io.ReadAll(c.Request.Body)
+	if err != nil {
+		var maxBytesErr *http.MaxBytesError
+		if errors.As(err, &maxBytesErr) {
+			respondBodyTooLarge(c, maxBytesErr.Limit)
+		} else {
+			respondError(c, http.StatusBadRequest, "request.body_unreadable")
+		}
+		return
+	}
+
+	patch, editToken, version, ok := parseMergePatch(c, body)
+	if !ok {
+		return
+	}
+
+	editor := editorFromRequest(c, editToken)
+	if editor.UserID == "" && editor.EditToken == "" {
+		respondError(c, http.StatusBadRequest, "request.edit_token_required")
+		return
+	}
+
+	paste, err := h.service.PatchPaste(c.Request.Context(), slug, editor, versionMatch(c, version), patch)
+	if err != nil {
+		handleServiceError(c, err)
+		return
+	}
+
+	localizeWarnings(c, paste.Warnings)
+	setETag(c, paste.Version, reprJSON)
+	c.JSON(http.StatusOK, paste)
+}
+
+// parseMergePatch разбирает документ патча. Ошибки всех полей собираются в один ответ 400.
+// edit_token и version - не поля пасты, а авторизация и предусловие.
+func parseMergePatch(c *gin.Context, body []byte) (patch service.PastePatch, editToken string, version *int64, ok bool) {
+	var doc map[string]json.RawMessage
+	if err := json.Unmarshal(body, &doc); err != nil || doc == nil {
+		respondError(c, http.StatusBadRequest, "request.invalid")
+		return patch, "", nil, false
+	}
+
+	fields := make([]string, 0, len(doc))
+	for field := range doc {
+		fields = append(fields, field)
+	}
+	sort.Strings(fields)
+
+	p := newProblem(c, http.StatusBadRequest, "request.invalid")
+	clearExpiresAt := false
+	for _, field := range fields {
+		raw := doc[field]
+		isNull := string(raw) == "null"
+
+		var err error
+		switch field {
+		case "content":
+			if isNull {
+				p.addField(c, field, "field.not_nullable", nil)
+				continue
+			}
+			var content string
+			err = json.Unmarshal(raw, &content)
+			patch.Content = &content
+		case "tags":
+			tags := []string{}
+			if !isNull {
+				err = json.Unmarshal(raw, &tags)
+			}
+			patch.Tags = &tags
+		case "visibility":
+			if isNull {
+				p.addField(c, field, "field.not_nullable", nil)
+				continue
+			}
+			var visibility string
+			err = json.Unmarshal(raw, &visibility)
+			patch.Visibility = &visibility
+		case "expires_in":
+			expiresIn := expiry.Never
+			if !isNull {
+				var in expiry.Input
+				err = json.Unmarshal(raw, &in)
+				expiresIn = string(in)
+			}
+			patch.ExpiresIn = &expiresIn
+		case "expires_at":
+			if isNull {
+				clearExpiresAt = true
+				continue
+			}
+			var expiresAt time.Time
+			err = json.Unmarshal(raw, &expiresAt)
+			patch.ExpiresAt = &expiresAt
+		case "title":
+			patch.Title, err = nullableString(raw)
+		case "description":
+			patch.Description, err = nullableString(raw)
+		case "filename":
+			patch.Filename, err = nullableString(raw)
+		case "language":
+			patch.Language, err = nullableString(raw)
+		case "edit_token":
+			err = json.Unmarshal(raw, &editToken)
+		case "version":
+			version = new(int64)
+			err = json.Unmarshal(raw, version)
+		default:
+			p.addField(c, field, "field.unknown", nil)
+			continue
+		}
+		if err != nil {
+			p.addField(c, field, "field.invalid_type", nil)
+		}
+	}
+
+	// "expires_at": null снимает срок, если вместе с ним не задан новый expires_in
+	if clearExpiresAt && patch.ExpiresIn == nil {
+		never := expiry.Never
+		patch.ExpiresIn = &never
+	}
+
+	if len(p.Errors) > 0 {
+		writeProblem(c, p)
+		return patch, "", nil, false
+	}
+	return patch, editToken, version, true
+}
+
+// nullableString читает строковое поле метаданных: null удаляет значение, как и пустая строка.
+func nullableString(raw json.RawMessage) (*string, error) {
+	s := ""
+	if string(raw) == "null" {
+		return &s, nil
+	}
+	if err := json.Unmarshal(raw, &s); err != nil {
+		return nil, err
+	}
+	return &s, nil
+}
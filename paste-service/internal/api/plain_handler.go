@@ -0,0 +1,61 @@
+package api
+
+import (
+	"mime"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// isPlainBody - тело пасты пришло как есть, а не JSON. application/x-www-form-urlencoded
+// сюда же: его по умолчанию ставит curl --data-binary, а разбирать форму смысла нет.
+func isPlainBody(c *gin.Context) bool {
+	mediaType, _, _ := mime.ParseMediaType(c.GetHeader("Content-Type"))
+	switch mediaType {
+	case "text/plain", "application/octet-stream", "application/x-www-form-urlencoded":
+		return true
+	}
+	return false
+}
+
+// handleCreatePlain создает пасту из сырого тела POST /api/pastes, чтобы работало
+// `cmd | curl --data-binary @- host/api/pastes`. Параметры - как у загрузки: query или X-Paste-*.
+// Ответ - текст: первая строка - ссылка на raw, вторая - токен редактирования.
+// Клиент, который просит JSON в Accept, получает тот же ответ, что и на JSON-запрос.
+func (h *Handler) handleCreatePlain(c *gin.Context) {
+	req, ok := parseUploadParams(c)
+	if !ok {
+		return
+	}
+	if !h.readUploadContent(c, &req) {
+		return
+	}
+
+	paste, err := h.service.CreatePaste(c.Request.Context(), req)
+	if err != nil {
+		handleServiceError(c, err)
+		return
+	}
+
+	localizeWarnings(c, paste.Warnings)
+	rawURL := h.baseURL(c) + "/api/pastes/" + url.PathEscape(paste.Slug) + "/raw"
+	c.Header("Location", rawURL)
+
+	// текстовый ответ - не представление пасты, ETag есть только у JSON
+	c.Header("Vary", "Accept")
+	if c.NegotiateFormat(gin.MIMEPlain, gin.MIMEJSON) == gin.MIMEJSON {
+		setETag(c, paste.Version, reprJSON)
+		c.JSON(http.StatusCreated, paste)
+		return
+	}
+
+	var b strings.Builder
+	b.WriteString(rawURL + "\n")
+	b.WriteString("edit_token: " + paste.EditToken + "\n")
+	for _, w := range paste.Warnings {
+		b.WriteString("warning: " + w.Message + "\n")
+	}
+	c.Data(http.StatusCreated, plainTextType, []byte(b.String()))
+}
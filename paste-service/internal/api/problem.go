@@ -0,0 +1,112 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+
+	"paste-service/internal/i18n"
+	"paste-service/internal/model"
+	"paste-service/internal/service"
+
+	"github.com/gin-gonic/gin"
+)
+
+const problemContentType = "application/problem+json"
+
+// problemTypePrefix + код ошибки дает type из RFC 7807. Коды стабильны, клиенты
+// могут ветвиться по ним, а не по тексту сообщения.
+const problemTypePrefix = "urn:paste-service:problem:"
+
+// Problem - тело ошибки в формате RFC 7807. Title и сообщения полей переводятся
+// по Accept-Language; Error дублирует Title для клиентов старого формата.
+type Problem struct {
+	Type     string         `json:"type"`
+	Title    string         `json:"title"`
+	Status   int            `json:"status"`
+	Detail   string         `json:"detail,omitempty"`
+	Instance string         `json:"instance,omitempty"`
+	Code     string         `json:"code"`
+	Errors   []FieldProblem `json:"errors,omitempty"`
+	Details  interface{}    `json:"details,omitempty"`
+	Error    string         `json:"error"`
+}
+
+// FieldProblem - ошибка конкретного поля запроса.
+type FieldProblem struct {
+	Field   string         `json:"field"`
+	Code    string         `json:"code"`
+	Message string         `json:"message"`
+	Params  map[string]any `json:"params,omitempty"`
+}
+
+func requestLang(c *gin.Context) i18n.Lang {
+	return i18n.Negotiate(c.GetHeader("Accept-Language"))
+}
+
+func localize(c *gin.Context, code string, params i18n.Params) string {
+	return i18n.Message(requestLang(c), code, params)
+}
+
+// localizeWarnings переводит предупреждения успешного ответа на язык запроса.
+func localizeWarnings(c *gin.Context, warnings []service.Warning) {
+	for i := range warnings {
+		warnings[i].Message = localize(c, warnings[i].MessageKey, nil)
+	}
+}
+
+func newProblem(c *gin.Context, status int, code string) *Problem {
+	title := localize(c, code, nil)
+	return &Problem{
+		Type:     problemTypePrefix + code,
+		Title:    title,
+		Status:   status,
+		Instance: c.Request.URL.Path,
+		Code:     code,
+		Error:    title,
+	}
+}
+
+// withFields добавляет ошибки полей из model.ValidationError, если они есть в цепочке err.
+func (p *Problem) withFields(c *gin.Context, err error) *Problem {
+	var validationErr *model.ValidationError
+	if !errors.As(err, &validationErr) {
+		return p
+	}
+	for _, f := range validationErr.Fields {
+		p.addField(c, f.Field, f.Code, f.Params)
+	}
+	return p
+}
+
+func (p *Problem) addField(c *gin.Context, field, code string, params map[string]any) {
+	p.Errors = append(p.Errors, FieldProblem{
+		Field:   field,
+		Code:    code,
+		Message: localize(c, code, params),
+		Params:  params,
+	})
+}
+
+// writeProblem отправляет ошибку и прерывает цепочку обработчиков.
+func writeProblem(c *gin.Context, p *Problem) {
+	lang := requestLang(c)
+	c.Header("Content-Type", problemContentType)
+	c.Header("Content-Language", string(lang))
+	c.Writer.Header().Add("Vary", "Accept-Language")
+	c.AbortWithStatusJSON(p.Status, p)
+}
+
+func respondError(c *gin.Context, status int, code string) {
+	writeProblem(c, newProblem(c, status, code))
+}
+
+// respondValidationError отвечает ошибкой с перечнем полей, не прошедших проверку.
+func respondValidationError(c *gin.Context, status int, code string, err error) {
+	writeProblem(c, newProblem(c, status, code).withFields(c, err))
+}
+
+func respondInvalidParam(c *gin.Context, param string) {
+	p := newProblem(c, http.StatusBadRequest, "request.invalid_param")
+	p.addField(c, param, "param.invalid", map[string]any{"param": param})
+	writeProblem(c, p)
+}
@@ -0,0 +1,72 @@
+package api
+
+import (
+	"log/slog"
+	"math"
+	"net/http"
+	"strconv"
+	"time"
+
+	"paste-service/internal/ratelimit"
+
+	"github.com/gin-gonic/gin"
+)
+
+// rateLimitMiddleware ограничивает запросы клиента отдельными бюджетами на чтение
+// и запись. Клиент - API-ключ, если он передан, иначе IP.
+func (h *Handler) rateLimitMiddleware() gin.HandlerFunc {
+	readLimit := ratelimit.PerMinute(h.cfg.Server.RateLimit)
+	writeLimit := ratelimit.PerMinute(h.cfg.Server.WriteRateLimit)
+
+	return func(c *gin.Context) {
+		class, limit := "read", readLimit
+		switch c.Request.Method {
+		case http.MethodGet, http.MethodHead, http.MethodOptions:
+		default:
+			class, limit = "write", writeLimit
+		}
+
+		if h.limiter == nil || !limit.Enabled() {
+			c.Next()
+			return
+		}
+
+		client := "ip:" + c.ClientIP()
+		if key := currentAPIKey(c); key != nil {
+			client = "key:" + key.ID
+		}
+
+		res, err := h.limiter.Take(c.Request.Context(), class+":"+client, limit)
+		if err != nil {
+			// хранилище недоступно - лучше пропустить запрос, чем уронить сервис
+			slog.WarnContext(c.Request.Context(), "rate limit check failed", slog.Any("error", err))
+			c.Next()
+			return
+		}
+
+		header := c.Writer.Header()
+		header.Set("RateLimit-Policy", strconv.Itoa(limit.Rate)+";w="+strconv.Itoa(int(limit.Period.Seconds())))
+		header.Set("RateLimit-Limit", strconv.Itoa(res.Limit))
+		header.Set("RateLimit-Remaining", strconv.Itoa(res.Remaining))
+		header.Set("RateLimit-Reset", strconv.Itoa(ceilSeconds(res.Reset)))
+
+		if !res.Allowed {
+			header.Set("Retry-After", strconv.Itoa(ceilSeconds(res.RetryAfter)))
+			slog.WarnContext(c.Request.Context(), "rate limit exceeded",
+				slog.String("class", class),
+				slog.String("client", client),
+			)
+			respondError(c, http.StatusTooManyRequests, "rate_limit.exceeded")
+			return
+		}
+
+		c.Next()
+	}
+}
+
+func ceilSeconds(d time.Duration) int {
+	if d <= 0 {
+		return 0
+	}
+	return int(math.Ceil(d.Seconds()))
+}
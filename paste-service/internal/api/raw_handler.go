@@ -0,0 +1,102 @@
+package api
+
+import (
+	"html/template"
+	"mime"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"paste-service/internal/service"
+
+	"github.com/gin-gonic/gin"
+)
+
+const plainTextType = "text/plain; charset=utf-8"
+
+// handleGetRaw отдает голый текст пасты. Range и If-Range обрабатывает http.ServeContent.
+func (h *Handler) handleGetRaw(c *gin.Context) {
+	paste, ok := h.readPaste(c, reprText)
+	if !ok {
+		return
+	}
+	serveRaw(c, paste)
+}
+
+// handleDownload - то же, что raw, но браузер сохраняет пасту файлом.
+func (h *Handler) handleDownload(c *gin.Context) {
+	paste, ok := h.readPaste(c, reprText)
+	if !ok {
+		return
+	}
+	c.Header("Content-Disposition", contentDisposition(paste))
+	serveRaw(c, paste)
+}
+
+// contentDisposition берет имя файла пасты, а без него или если имя не кодируется - slug.
+func contentDisposition(paste *service.PasteResponse) string {
+	if paste.Filename != "" {
+		if v := mime.FormatMediaType("attachment", map[string]string{"filename": paste.Filename}); v != "" {
+			return v
+		}
+	}
+	return mime.FormatMediaType("attachment", map[string]string{"filename": paste.Slug + ".txt"})
+}
+
+func serveRaw(c *gin.Context, paste *service.PasteResponse) {
+	// без nosniff браузер может отрисовать текст с разметкой как HTML
+	c.Header("Content-Type", plainTextType)
+	c.Header("X-Content-Type-Options", "nosniff")
+	http.ServeContent(c.Writer, c.Request, "", paste.UpdatedAt, strings.NewReader(paste.Content))
+}
+
+var pastePage = template.Must(template.New("paste").Parse(`<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<meta name="viewport" content="width=device-width, initial-scale=1">
+<title>{{with .Title}}{{.}}{{else}}{{.Slug}}{{end}}</title>
+<style>
+body { margin: 0; font-family: sans-serif; }
+header { padding: 8px 16px; border-bottom: 1px solid #ddd; }
+header a { margin-left: 12px; }
+p { margin: 0; padding: 8px 16px; color: #555; }
+pre { margin: 0; padding: 16px; white-space: pre-wrap; word-break: break-word; }
+</style>
+</head>
+<body>
+<header><strong>{{with .Title}}{{.}}{{else}}{{.Slug}}{{end}}</strong>{{with .Filename}} {{.}}{{end}}{{with .Language}} <code>{{.}}</code>{{end}}{{range .Tags}} <code>{{.}}</code>{{end}}<a href="{{.RawURL}}">raw</a><a href="{{.DownloadURL}}">download</a></header>
+{{with .Description}}<p>{{.}}</p>{{end}}
+<pre>{{.Content}}</pre>
+</body>
+</html>
+`))
+
+type pastePageData struct {
+	*service.PasteResponse
+	RawURL      string
+	DownloadURL string
+}
+
+// servePastePage отдает пасту страницей для браузера. Содержимое экранирует html/template,
+// а CSP запрещает скрипты на случай ошибки в шаблоне.
+func servePastePage(c *gin.Context, paste *service.PasteResponse) {
+	// ссылка для чтения из query должна дойти и до raw, иначе приватная паста не откроется
+	suffix := ""
+	if share := c.Query("share"); share != "" {
+		suffix = "?" + url.Values{"share": {share}}.Encode()
+	}
+	base := url.PathEscape(paste.Slug)
+
+	c.Header("Content-Security-Policy", "default-src 'none'; style-src 'unsafe-inline'")
+	c.Header("X-Content-Type-Options", "nosniff")
+	c.Header("Content-Type", "text/html; charset=utf-8")
+	c.Status(http.StatusOK)
+	if err := pastePage.Execute(c.Writer, pastePageData{
+		PasteResponse: paste,
+		RawURL:        base + "/raw" + suffix,
+		DownloadURL:   base + "/download" + suffix,
+	}); err != nil {
+		_ = c.Error(err)
+	}
+}
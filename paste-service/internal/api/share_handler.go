@@ -0,0 +1,104 @@
+package api
+
+import (
+	"net/http"
+	"net/url"
+	"strings"
+
+	"paste-service/internal/expiry"
+	"paste-service/internal/service"
+
+	"github.com/gin-gonic/gin"
+)
+
+type CreateShareLinkRequest struct {
+	EditToken string           `json:"edit_token"`
+	ExpiresIn *expiry.Duration `json:"expires_in,omitempty"`
+	MaxViews  int              `json:"max_views,omitempty"`
+}
+
+type ShareLinkCreatedResponse struct {
+	service.ShareLinkResponse
+	URL string `json:"url"`
+}
+
+func (h *Handler) handleCreateShareLink(c *gin.Context) {
+	slug := c.Param("slug")
+	if slug == "" {
+		respondError(c, http.StatusBadRequest, "request.slug_required")
+		return
+	}
+
+	var req CreateShareLinkRequest
+	if c.Request.ContentLength != 0 {
+		if !bindJSON(c, &req) {
+			return
+		}
+	}
+	editor := editorFromRequest(c, req.EditToken)
+	if editor.UserID == "" && editor.EditToken == "" {
+		respondError(c, http.StatusBadRequest, "request.edit_token_required")
+		return
+	}
+
+	link, err := h.service.CreateShareLink(c.Request.Context(), slug, editor, service.CreateShareLinkRequest{
+		ExpiresIn: req.ExpiresIn.Ptr(),
+		MaxViews:  req.MaxViews,
+	})
+	if err != nil {
+		handleServiceError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, ShareLinkCreatedResponse{
+		ShareLinkResponse: *link,
+		URL:               h.shareURL(c, slug, link.Token),
+	})
+}
+
+func (h *Handler) handleListShareLinks(c *gin.Context) {
+	editor := editorFromRequest(c, "")
+	if editor.UserID == "" && editor.EditToken == "" {
+		respondError(c, http.StatusBadRequest, "request.edit_token_required")
+		return
+	}
+
+	links, err := h.service.ListShareLinks(c.Request.Context(), c.Param("slug"), editor)
+	if err != nil {
+		handleServiceError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, links)
+}
+
+func (h *Handler) handleRevokeShareLink(c *gin.Context) {
+	editor := editorFromRequest(c, "")
+	if editor.UserID == "" && editor.EditToken == "" {
+		respondError(c, http.StatusBadRequest, "request.edit_token_required")
+		return
+	}
+
+	if err := h.service.RevokeShareLink(c.Request.Context(), c.Param("slug"), editor, c.Param("id")); err != nil {
+		handleServiceError(c, err)
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+func (h *Handler) shareURL(c *gin.Context, slug, token string) string {
+	return h.baseURL(c) + "/api/pastes/" + url.PathEscape(slug) + "?share=" + url.QueryEscape(token)
+}
+
+// baseURL - SERVER_PUBLICURL, а без него - адрес из запроса.
+func (h *Handler) baseURL(c *gin.Context) string {
+	if base := strings.TrimRight(h.cfg.Server.PublicURL, "/"); base != "" {
+		return base
+	}
+	scheme := "http"
+	if c.Request.TLS != nil {
+		scheme = "https"
+	}
+	return scheme + "://" + c.Request.Host
+}
@@ -0,0 +1,208 @@
+package api
+
+import (
+	"errors"
+	"io"
+	"mime"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"paste-service/internal/model"
+	"paste-service/internal/service"
+
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	uploadPath      = "/api/pastes/upload"
+	uploadFormField = "file"
+)
+
+// Заголовки-альтернативы query-параметрам для загрузки и текстового создания:
+// query иногда неудобно собирать в шелле.
+var uploadParamHeaders = []struct{ param, header string }{
+	{"tags", "X-Paste-Tags"},
+	{"visibility", "X-Paste-Visibility"},
+	{"expires_in", "X-Paste-Expires-In"},
+	{"expires_at", "X-Paste-Expires-At"},
+	{"auto_tag", "X-Paste-Auto-Tag"},
+	{"title", "X-Paste-Title"},
+	{"description", "X-Paste-Description"},
+	{"filename", "X-Paste-Filename"},
+	{"language", "X-Paste-Language"},
+}
+
+var errNoUploadFile = errors.New("в multipart нет поля file")
+
+// handleUploadPaste создает пасту из сырого тела или из поля file в multipart/form-data.
+// Тело читается потоком прямо в строку, без промежуточного разбора формы на диск
+// и без копий под JSON. Параметры пасты передаются в query.
+func (h *Handler) handleUploadPaste(c *gin.Context) {
+	if !checkKeyScope(c, model.ScopePasteCreate) {
+		return
+	}
+
+	req, ok := parseUploadParams(c)
+	if !ok {
+		return
+	}
+
+	if !h.readUploadContent(c, &req) {
+		return
+	}
+
+	paste, err := h.service.CreatePaste(c.Request.Context(), req)
+	if err != nil {
+		handleServiceError(c, err)
+		return
+	}
+
+	localizeWarnings(c, paste.Warnings)
+	setETag(c, paste.Version, reprJSON)
+	c.JSON(http.StatusCreated, paste)
+}
+
+// readUploadContent читает тело в req.Content и проверяет, что это текст.
+// Содержимое больше, чем разрешает класс квот клиента, обрывается при чтении:
+// 413 приходит раньше, чем тело целиком окажется в памяти.
+func (h *Handler) readUploadContent(c *gin.Context, req *service.CreatePasteRequest) bool {
+	limit := h.service.MaxPasteSize(service.QuotaSubject{
+		APIKeyID: req.APIKeyID,
+		UserID:   req.OwnerID,
+		ClientIP: req.ClientIP,
+	})
+	content, filename, err := readUpload(c.Request, limit)
+	if err != nil {
+		var maxBytesErr *http.MaxBytesError
+		switch {
+		case errors.As(err, &maxBytesErr):
+			respondBodyTooLarge(c, maxBytesErr.Limit)
+		case errors.Is(err, errNoUploadFile):
+			respondError(c, http.StatusBadRequest, "request.file_required")
+		default:
+			respondError(c, http.StatusBadRequest, "request.body_unreadable")
+		}
+		return false
+	}
+
+	if !utf8.ValidString(content) || strings.IndexByte(content, 0) >= 0 {
+		respondError(c, http.StatusUnsupportedMediaType, "request.content_not_text")
+		return false
+	}
+	req.Content = content
+	if req.Filename == "" {
+		req.Filename = filename
+	}
+	return true
+}
+
+// uploadParam берет параметр из query, а если его там нет - из заголовка X-Paste-*.
+func uploadParam(c *gin.Context, name string) string {
+	if v := c.Query(name); v != "" {
+		return v
+	}
+	for _, p := range uploadParamHeaders {
+		if p.param == name {
+			return c.GetHeader(p.header)
+		}
+	}
+	return ""
+}
+
+func parseUploadParams(c *gin.Context) (service.CreatePasteRequest, bool) {
+	req := service.CreatePasteRequest{
+		Visibility:  uploadParam(c, "visibility"),
+		Title:       uploadParam(c, "title"),
+		Description: uploadParam(c, "description"),
+		Filename:    uploadParam(c, "filename"),
+		Language:    uploadParam(c, "language"),
+	}
+	setCreator(c, &req)
+
+	if tags := uploadParam(c, "tags"); tags != "" {
+		for _, tag := range strings.Split(tags, ",") {
+			if tag = strings.TrimSpace(tag); tag != "" {
+				req.Tags = append(req.Tags, tag)
+			}
+		}
+	}
+
+	// expires_in разбирает сервис, чтобы ошибка пришла с тем же кодом, что и для JSON
+	req.ExpiresIn = uploadParam(c, "expires_in")
+	if v := uploadParam(c, "expires_at"); v != "" {
+		expiresAt, err := time.Parse(time.RFC3339, v)
+		if err != nil {
+			respondInvalidParam(c, "expires_at")
+			return req, false
+		}
+		req.ExpiresAt = &expiresAt
+	}
+
+	if v := uploadParam(c, "auto_tag"); v != "" {
+		autoTag, err := strconv.ParseBool(v)
+		if err != nil {
+			respondInvalidParam(c, "auto_tag")
+			return req, false
+		}
+		req.AutoTag = autoTag
+	}
+
+	return req, true
+}
+
+// readUpload читает содержимое один раз. Для multipart берется первое поле file,
+// остальные части пропускаются; имя файла из него - имя пасты по умолчанию.
+// limit ограничивает само содержимое, без обвязки multipart; 0 - без ограничения.
+func readUpload(r *http.Request, limit int64) (content, filename string, err error) {
+	mediaType, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type"))
+	if mediaType != "multipart/form-data" {
+		if limit > 0 && r.ContentLength > limit {
+			return "", "", &http.MaxBytesError{Limit: limit}
+		}
+		content, err = readAll(limitBody(r.Body, limit), r.ContentLength)
+		return content, "", err
+	}
+
+	reader, err := r.MultipartReader()
+	if err != nil {
+		return "", "", err
+	}
+	for {
+		part, err := reader.NextPart()
+		if err == io.EOF {
+			return "", "", errNoUploadFile
+		}
+		if err != nil {
+			return "", "", err
+		}
+		if part.FormName() == uploadFormField {
+			defer part.Close()
+			content, err = readAll(limitBody(part, limit), -1)
+			// FileName уже без каталогов
+			return content, part.FileName(), err
+		}
+		part.Close()
+	}
+}
+
+// limitBody обрывает чтение на limit байтах с *http.MaxBytesError, как и лимит тела.
+func limitBody(r io.ReadCloser, limit int64) io.Reader {
+	if limit <= 0 {
+		return r
+	}
+	return http.MaxBytesReader(nil, r, limit)
+}
+
+func readAll(r io.Reader, sizeHint int64) (string, error) {
+	var b strings.Builder
+	if sizeHint > 0 {
+		b.Grow(int(sizeHint))
+	}
+	if _, err := io.Copy(&b, r); err != nil {
+		return "", err
+	}
+	return b.String(), nil
+}
@@ -1,11 +1,16 @@
 package cache
 
-import "time"
+import (
+	"context"
+	"time"
+)
+
+const tracerName = "paste-service/internal/cache"
 
 type Cache interface {
-	Get(key string) (interface{}, bool)
-	GetTyped(key string, result interface{}) bool
-	Set(key string, value interface{}, ttl time.Duration)
-	Invalidate(key string)
-	Clear()
+	Get(ctx context.Context, key string) (interface{}, bool)
+	GetTyped(ctx context.Context, key string, result interface{}) bool
+	Set(ctx context.Context, key string, value interface{}, ttl time.Duration)
+	Invalidate(ctx context.Context, key string)
+	Clear(ctx context.Context)
 }
@@ -3,10 +3,15 @@
 package cache
 
 import (
+	"context"
 	"encoding/json"
 	"reflect"
 	"sync"
 	"time"
+
+	"paste-service/internal/telemetry"
+
+	"go.opentelemetry.io/otel/attribute"
 )
 
 type entry struct {
@@ -31,7 +36,19 @@ func NewInMemoryCache(refreshTTL bool) *InMemoryCache {
 	return cache
 }
 
-func (c *InMemoryCache) Get(key string) (interface{}, bool) {
+func (c *InMemoryCache) Get(ctx context.Context, key string) (interface{}, bool) {
+	_, span := telemetry.Start(ctx, tracerName, "InMemoryCache.Get",
+		attribute.String("cache.backend", "inmemory"),
+		attribute.String("cache.key", key),
+	)
+	defer span.End()
+
+	value, ok := c.get(key)
+	span.SetAttributes(attribute.Bool("cache.hit", ok))
+	return value, ok
+}
+
+func (c *InMemoryCache) get(key string) (interface{}, bool) {
 	c.mu.RLock()
 	ent, exists := c.items[key]
 
@@ -63,8 +80,8 @@ func (c *InMemoryCache) Get(key string) (interface{}, bool) {
 	return ent.value, true
 }
 
-func (c *InMemoryCache) GetTyped(key string, result interface{}) bool {
-	value, ok := c.Get(key)
+func (c *InMemoryCache) GetTyped(ctx context.Context, key string, result interface{}) bool {
+	value, ok := c.Get(ctx, key)
 	if !ok {
 		return false
 	}
@@ -94,7 +111,13 @@ func (c *InMemoryCache) GetTyped(key string, result interface{}) bool {
 	return true
 }
 
-func (c *InMemoryCache) Set(key string, value interface{}, ttl time.Duration) {
+func (c *InMemoryCache) Set(ctx context.Context, key string, value interface{}, ttl time.Duration) {
+	_, span := telemetry.Start(ctx, tracerName, "InMemoryCache.Set",
+		attribute.String("cache.backend", "inmemory"),
+		attribute.String("cache.key", key),
+	)
+	defer span.End()
+
 	var expire int64
 	if ttl > 0 {
 		expire = time.Now().Add(ttl).UnixNano()
@@ -104,13 +127,24 @@ func (c *InMemoryCache) Set(key string, value interface{}, ttl time.Duration) {
 	c.mu.Unlock()
 }
 
-func (c *InMemoryCache) Invalidate(key string) {
+func (c *InMemoryCache) Invalidate(ctx context.Context, key string) {
+	_, span := telemetry.Start(ctx, tracerName, "InMemoryCache.Invalidate",
+		attribute.String("cache.backend", "inmemory"),
+		attribute.String("cache.key", key),
+	)
+	defer span.End()
+
 	c.mu.Lock()
 	delete(c.items, key)
 	c.mu.Unlock()
 }
 
-func (c *InMemoryCache) Clear() {
+func (c *InMemoryCache) Clear(ctx context.Context) {
+	_, span := telemetry.Start(ctx, tracerName, "InMemoryCache.Clear",
+		attribute.String("cache.backend", "inmemory"),
+	)
+	defer span.End()
+
 	c.mu.Lock()
 	c.items = make(map[string]entry)
 	c.mu.Unlock()
@@ -3,11 +3,14 @@ package cache
 import (
 	"context"
 	"encoding/json"
-	"log"
+	"log/slog"
 	"sync"
 	"time"
 
+	"paste-service/internal/telemetry"
+
 	"github.com/redis/go-redis/v9"
+	"go.opentelemetry.io/otel/attribute"
 )
 
 var (
@@ -17,20 +20,27 @@ var (
 
 type RedisCache struct {
 	client *redis.Client
-	ctx    context.Context
 }
 
-func (c *RedisCache) GetTyped(key string, result interface{}) bool {
-	val, err := c.client.Get(c.ctx, key).Result()
+func (c *RedisCache) GetTyped(ctx context.Context, key string, result interface{}) bool {
+	ctx, span := telemetry.Start(ctx, tracerName, "RedisCache.GetTyped",
+		attribute.String("cache.backend", "redis"),
+		attribute.String("cache.key", key),
+	)
+	defer span.End()
+
+	val, err := c.client.Get(ctx, key).Result()
+	span.SetAttributes(attribute.Bool("cache.hit", err == nil))
 	if err != nil {
 		if err != redis.Nil {
-			log.Printf("Ошибка получениия из Redis для ключа %s: %v", key, err)
+			telemetry.RecordError(span, err)
+			slog.ErrorContext(ctx, "redis get failed", slog.String("key", key), slog.Any("error", err))
 		}
 		return false
 	}
 
 	if err := json.Unmarshal([]byte(val), result); err != nil {
-		log.Printf("Ошибка десериализации JSON для ключа %s: %v", key, err)
+		slog.WarnContext(ctx, "redis value unmarshal failed", slog.String("key", key), slog.Any("error", err))
 		return false
 	}
 
@@ -50,11 +60,11 @@ func NewRedisCache(redisURL string) (*RedisCache, error) {
 		return nil, err
 	}
 
-	log.Printf("Успешное подключениие к Redis: %s", redisURL)
+	// в URL может быть пароль, логируем только адрес
+	slog.Info("connected to redis", slog.String("addr", options.Addr), slog.Int("db", options.DB))
 
 	cache := &RedisCache{
 		client: client,
-		ctx:    ctx,
 	}
 
 	redisInstancesLock.Lock()
@@ -64,11 +74,19 @@ func NewRedisCache(redisURL string) (*RedisCache, error) {
 	return cache, nil
 }
 
-func (c *RedisCache) Get(key string) (interface{}, bool) {
-	val, err := c.client.Get(c.ctx, key).Result()
+func (c *RedisCache) Get(ctx context.Context, key string) (interface{}, bool) {
+	ctx, span := telemetry.Start(ctx, tracerName, "RedisCache.Get",
+		attribute.String("cache.backend", "redis"),
+		attribute.String("cache.key", key),
+	)
+	defer span.End()
+
+	val, err := c.client.Get(ctx, key).Result()
+	span.SetAttributes(attribute.Bool("cache.hit", err == nil))
 	if err != nil {
 		if err != redis.Nil {
-			log.Printf("Ошибка получения из Redis для ключа %s: %v", key, err)
+			telemetry.RecordError(span, err)
+			slog.ErrorContext(ctx, "redis get failed", slog.String("key", key), slog.Any("error", err))
 		}
 		return nil, false
 	}
@@ -81,7 +99,13 @@ func (c *RedisCache) Get(key string) (interface{}, bool) {
 	return result, true
 }
 
-func (c *RedisCache) Set(key string, value interface{}, ttl time.Duration) {
+func (c *RedisCache) Set(ctx context.Context, key string, value interface{}, ttl time.Duration) {
+	ctx, span := telemetry.Start(ctx, tracerName, "RedisCache.Set",
+		attribute.String("cache.backend", "redis"),
+		attribute.String("cache.key", key),
+	)
+	defer span.End()
+
 	var data string
 	switch v := value.(type) {
 	case string:
@@ -89,34 +113,52 @@ func (c *RedisCache) Set(key string, value interface{}, ttl time.Duration) {
 	default:
 		jsonData, err := json.Marshal(value)
 		if err != nil {
-			log.Printf("Ошибка сериализации в JSON для ключа %s: %v", key, err)
+			slog.ErrorContext(ctx, "redis value marshal failed", slog.String("key", key), slog.Any("error", err))
 			return
 		}
 		data = string(jsonData)
 	}
 
-	err := c.client.Set(c.ctx, key, data, ttl).Err()
+	err := c.client.Set(ctx, key, data, ttl).Err()
 	if err != nil {
-		log.Printf("Ошибка установки в Redis для ключа %s: %v", key, err)
+		telemetry.RecordError(span, err)
+		slog.ErrorContext(ctx, "redis set failed", slog.String("key", key), slog.Any("error", err))
 	}
 }
 
-func (c *RedisCache) Invalidate(key string) {
-	err := c.client.Del(c.ctx, key).Err()
+func (c *RedisCache) Invalidate(ctx context.Context, key string) {
+	ctx, span := telemetry.Start(ctx, tracerName, "RedisCache.Invalidate",
+		attribute.String("cache.backend", "redis"),
+		attribute.String("cache.key", key),
+	)
+	defer span.End()
+
+	err := c.client.Del(ctx, key).Err()
 	if err != nil {
-		log.Printf("Ошибка удаления из Redis для ключа %s: %v", key, err)
+		telemetry.RecordError(span, err)
+		slog.ErrorContext(ctx, "redis delete failed", slog.String("key", key), slog.Any("error", err))
 	}
 }
 
-func (c *RedisCache) Clear() {
-	err := c.client.FlushAll(c.ctx).Err()
+func (c *RedisCache) Clear(ctx context.Context) {
+	ctx, span := telemetry.Start(ctx, tracerName, "RedisCache.Clear",
+		attribute.String("cache.backend", "redis"),
+	)
+	defer span.End()
+
+	err := c.client.FlushAll(ctx).Err()
 	if err != nil {
-		log.Printf("Ошибка очистки Redis: %v", err)
+		telemetry.RecordError(span, err)
+		slog.ErrorContext(ctx, "redis flush failed", slog.Any("error", err))
 	} else {
-		log.Println("Redis кэш полностью очищен")
+		slog.InfoContext(ctx, "redis cache flushed")
 	}
 }
 
+func (c *RedisCache) Ping(ctx context.Context) error {
+	return c.client.Ping(ctx).Err()
+}
+
 func (c *RedisCache) Close() error {
 	return c.client.Close()
 }
@@ -127,7 +169,7 @@ func CloseRedisConnections() {
 
 	for _, instance := range redisInstances {
 		if err := instance.Close(); err != nil {
-			log.Printf("Ошибка при закрытии соединения с Redis: %v", err)
+			slog.Error("redis close failed", slog.Any("error", err))
 		}
 	}
 
@@ -3,19 +3,27 @@ package sluggen
 import (
 	"context"
 	"errors"
+	"fmt"
 	"time"
 
+	"paste-service/internal/telemetry"
+
+	"go.opentelemetry.io/contrib/instrumentation/google.golang.org/grpc/otelgrpc"
+	"go.opentelemetry.io/otel/attribute"
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/connectivity"
 	"google.golang.org/grpc/credentials/insecure"
 )
 
+const tracerName = "paste-service/internal/clients/sluggen"
+
 var (
 	ErrSlugGeneratorUnavailable = errors.New("сервис генерации slug недоступен")
 	ErrInvalidResponse          = errors.New("некорректный ответ от сервиса генерации slug")
 )
 
 type SlugClient interface {
-	GenerateSlug(content string, tags []string) (string, error)
+	GenerateSlug(ctx context.Context, content string, tags []string) (string, error)
 }
 
 type Config struct {
@@ -47,6 +55,8 @@ func NewGRPCClient(cfg Config) (*GRPCClient, error) {
 		ctx,
 		cfg.Address,
 		grpc.WithTransportCredentials(insecure.NewCredentials()),
+		// stats handler кладет traceparent в gRPC metadata
+		grpc.WithStatsHandler(otelgrpc.NewClientHandler()),
 		grpc.WithBlock(),
 	)
 	if err != nil {
@@ -65,12 +75,37 @@ func (c *GRPCClient) Close() error {
 	return c.conn.Close()
 }
 
-func (c *GRPCClient) GenerateSlug(content string, tags []string) (string, error) {
+// Ping ждет, пока соединение не окажется в READY, или до истечения ctx.
+func (c *GRPCClient) Ping(ctx context.Context) error {
+	for {
+		state := c.conn.GetState()
+		switch state {
+		case connectivity.Ready:
+			return nil
+		case connectivity.Idle:
+			c.conn.Connect()
+		case connectivity.Shutdown:
+			return fmt.Errorf("%w: соединение закрыто", ErrSlugGeneratorUnavailable)
+		}
+
+		if !c.conn.WaitForStateChange(ctx, state) {
+			return fmt.Errorf("%w: состояние %s", ErrSlugGeneratorUnavailable, state)
+		}
+	}
+}
+
+func (c *GRPCClient) GenerateSlug(ctx context.Context, content string, tags []string) (_ string, err error) {
+	ctx, span := telemetry.Start(ctx, tracerName, "GRPCClient.GenerateSlug",
+		attribute.Int("sluggen.content_size", len(content)),
+		attribute.Int("sluggen.tags_count", len(tags)),
+	)
+	defer func() { telemetry.End(span, err) }()
+
 	if len(content) > c.maxTextSize {
 		content = content[:c.maxTextSize]
 	}
 
-	ctx, cancel := context.WithTimeout(context.Background(), c.timeout)
+	ctx, cancel := context.WithTimeout(ctx, c.timeout)
 	defer cancel()
 
 	resp, err := c.client.GenerateSlug(ctx, &GenerateSlugRequest{
@@ -100,7 +135,7 @@ func NewMockClient(slug string, err error) *MockClient {
 	}
 }
 
-func (c *MockClient) GenerateSlug(content string, tags []string) (string, error) {
+func (c *MockClient) GenerateSlug(ctx context.Context, content string, tags []string) (string, error) {
 	if c.err != nil {
 		return "", c.err
 	}
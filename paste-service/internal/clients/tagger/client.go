@@ -2,20 +2,28 @@ package tagger
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"errors"
 	"fmt"
 	"net/http"
 	"time"
+
+	"paste-service/internal/telemetry"
+
+	"go.opentelemetry.io/contrib/instrumentation/net/http/otelhttp"
+	"go.opentelemetry.io/otel/attribute"
 )
 
+const tracerName = "paste-service/internal/clients/tagger"
+
 var (
 	ErrTaggerUnavailable = errors.New("сервис тегирования недоступен")
 	ErrInvalidResponse   = errors.New("некорректный ответ от сервиса тегирования")
 )
 
 type TaggerClient interface {
-	GetTags(text string) ([]string, error)
+	GetTags(ctx context.Context, text string) ([]string, error)
 }
 
 type Config struct {
@@ -50,13 +58,20 @@ func NewHTTPClient(cfg Config) *HTTPClient {
 	return &HTTPClient{
 		client: &http.Client{
 			Timeout: cfg.Timeout,
+			// транспорт добавляет traceparent в исходящие запросы
+			Transport: otelhttp.NewTransport(http.DefaultTransport),
 		},
 		baseURL:     cfg.BaseURL,
 		maxTextSize: cfg.MaxTextSize,
 	}
 }
 
-func (c *HTTPClient) GetTags(text string) ([]string, error) {
+func (c *HTTPClient) GetTags(ctx context.Context, text string) (_ []string, err error) {
+	ctx, span := telemetry.Start(ctx, tracerName, "HTTPClient.GetTags",
+		attribute.Int("tagger.text_size", len(text)),
+	)
+	defer func() { telemetry.End(span, err) }()
+
 	if len(text) > c.maxTextSize {
 		text = text[:c.maxTextSize]
 	}
@@ -67,11 +82,18 @@ func (c *HTTPClient) GetTags(text string) ([]string, error) {
 		return nil, err
 	}
 
-	resp, err := c.client.Post(
+	req, err := http.NewRequestWithContext(
+		ctx,
+		http.MethodPost,
 		fmt.Sprintf("%s/api/tags", c.baseURL),
-		"application/json",
 		bytes.NewBuffer(jsonData),
 	)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := c.client.Do(req)
 	if err != nil {
 		return nil, ErrTaggerUnavailable
 	}
@@ -89,6 +111,26 @@ func (c *HTTPClient) GetTags(text string) ([]string, error) {
 	return response.Tags, nil
 }
 
+// Ping проверяет, что сервис тэгирования отвечает. Любой ответ кроме 5xx считаем доступностью.
+func (c *HTTPClient) Ping(ctx context.Context) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, fmt.Sprintf("%s/health", c.baseURL), nil)
+	if err != nil {
+		return err
+	}
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("%w: %v", ErrTaggerUnavailable, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= http.StatusInternalServerError {
+		return fmt.Errorf("%w: код ответа %d", ErrTaggerUnavailable, resp.StatusCode)
+	}
+
+	return nil
+}
+
 type MockClient struct {
 	tags []string
 	err  error
@@ -101,7 +143,7 @@ func NewMockClient(tags []string, err error) *MockClient {
 	}
 }
 
-func (c *MockClient) GetTags(text string) ([]string, error) {
+func (c *MockClient) GetTags(ctx context.Context, text string) ([]string, error) {
 	if c.err != nil {
 		return nil, c.err
 	}
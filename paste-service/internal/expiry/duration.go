@@ -0,0 +1,119 @@
+// Package expiry разбирает сроки жизни из запросов и применяет к ним политику сервиса.
+package expiry
+
+import (
+	"encoding/json"
+	"errors"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	Day  = 24 * time.Hour
+	Week = 7 * Day
+)
+
+var ErrInvalidDuration = errors.New("некорректная длительность")
+
+var units = map[string]time.Duration{
+	"ns": time.Nanosecond,
+	"us": time.Microsecond,
+	"µs": time.Microsecond,
+	"ms": time.Millisecond,
+	"s":  time.Second,
+	"m":  time.Minute,
+	"h":  time.Hour,
+	"d":  Day,
+	"w":  Week,
+}
+
+// ParseDuration понимает все, что понимает time.ParseDuration, плюс дни и недели:
+// "7d", "1w", "1d12h". Отрицательные длительности не принимаются.
+func ParseDuration(s string) (time.Duration, error) {
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return 0, ErrInvalidDuration
+	}
+
+	var total float64
+	for s != "" {
+		i := 0
+		for i < len(s) && (s[i] == '.' || ('0' <= s[i] && s[i] <= '9')) {
+			i++
+		}
+		if i == 0 {
+			return 0, ErrInvalidDuration
+		}
+		value, err := strconv.ParseFloat(s[:i], 64)
+		if err != nil {
+			return 0, ErrInvalidDuration
+		}
+		s = s[i:]
+
+		j := 0
+		for j < len(s) && s[j] != '.' && (s[j] < '0' || s[j] > '9') {
+			j++
+		}
+		unit, ok := units[s[:j]]
+		if !ok {
+			return 0, ErrInvalidDuration
+		}
+		s = s[j:]
+
+		total += value * float64(unit)
+		if total > float64(1<<63-1) {
+			return 0, ErrInvalidDuration
+		}
+	}
+	return time.Duration(total), nil
+}
+
+// Format печатает длительность коротко: целые сутки - в днях, остальное без хвостовых нулей.
+func Format(d time.Duration) string {
+	if d > 0 && d%Day == 0 {
+		return strconv.FormatInt(int64(d/Day), 10) + "d"
+	}
+	s := d.String()
+	if strings.HasSuffix(s, "m0s") {
+		s = s[:len(s)-2]
+	}
+	if strings.HasSuffix(s, "h0m") {
+		s = s[:len(s)-2]
+	}
+	return s
+}
+
+// Duration - длительность в JSON: число наносекунд, как раньше, или строка ParseDuration.
+type Duration time.Duration
+
+func (d *Duration) UnmarshalJSON(data []byte) error {
+	var s string
+	if err := json.Unmarshal(data, &s); err != nil {
+		var ns int64
+		if err := json.Unmarshal(data, &ns); err != nil {
+			return ErrInvalidDuration
+		}
+		*d = Duration(ns)
+		return nil
+	}
+	parsed, err := ParseDuration(s)
+	if err != nil {
+		return err
+	}
+	*d = Duration(parsed)
+	return nil
+}
+
+func (d Duration) MarshalJSON() ([]byte, error) {
+	return json.Marshal(Format(time.Duration(d)))
+}
+
+// Ptr переводит необязательное значение из запроса в *time.Duration.
+func (d *Duration) Ptr() *time.Duration {
+	if d == nil {
+		return nil
+	}
+	v := time.Duration(*d)
+	return &v
+}
@@ -0,0 +1,118 @@
+package expiry
+
+import (
+	"encoding/json"
+	"errors"
+	"strconv"
+	"time"
+
+	"paste-service/config"
+	"paste-service/internal/model"
+)
+
+// Never - значение expires_in для бессрочной пасты.
+const Never = "never"
+
+// Presets - именованные сроки, которые можно передать в expires_in вместо длительности.
+var Presets = map[string]time.Duration{
+	"hour":  time.Hour,
+	"day":   Day,
+	"week":  Week,
+	"month": 30 * Day,
+	"year":  365 * Day,
+}
+
+var (
+	ErrInvalidExpiry   = errors.New("некорректный срок действия")
+	ErrExpiryConflict  = errors.New("нельзя указать expires_in и expires_at одновременно")
+	ErrExpiryRequired  = errors.New("не указан срок действия")
+	ErrExpiryInPast    = errors.New("срок действия уже прошел")
+	ErrExpiryTooShort  = errors.New("срок действия меньше минимального")
+	ErrExpiryTooLong   = errors.New("срок действия больше максимального")
+	ErrNeverNotAllowed = errors.New("бессрочные пасты запрещены")
+)
+
+// Input - значение expires_in из JSON: число наносекунд (старый формат), строка
+// длительности ("90m", "7d", "1w") или имя пресета ("day", "never"). Разбирается
+// в Policy.Resolve, чтобы ошибка пришла клиенту с указанием поля.
+type Input string
+
+func (in *Input) UnmarshalJSON(data []byte) error {
+	var s string
+	if err := json.Unmarshal(data, &s); err == nil {
+		*in = Input(s)
+		return nil
+	}
+	var ns int64
+	if err := json.Unmarshal(data, &ns); err != nil {
+		return ErrInvalidDuration
+	}
+	*in = Input(strconv.FormatInt(ns, 10) + "ns")
+	return nil
+}
+
+// Policy - ограничения срока жизни из конфига.
+type Policy struct {
+	cfg config.ExpiryConfig
+}
+
+func NewPolicy(cfg config.ExpiryConfig) Policy {
+	return Policy{cfg: cfg}
+}
+
+// Resolve превращает expires_in или expires_at в момент истечения. nil - паста бессрочная.
+// Без обоих значений действует EXPIRY_DEFAULT, а если он 0 при заданном EXPIRY_MAX - максимум.
+// required запрещает пустой запрос: при смене срока молчаливый дефолт был бы сюрпризом.
+func (p Policy) Resolve(in string, at *time.Time, now time.Time, required bool) (*time.Time, error) {
+	if in != "" && at != nil {
+		return nil, model.Invalid("expires_at", "expires.conflict", ErrExpiryConflict, nil)
+	}
+
+	switch {
+	case at != nil:
+		if !at.After(now) {
+			return nil, model.Invalid("expires_at", "expires_at.past", ErrExpiryInPast, nil)
+		}
+		return p.check("expires_at", *at, at.Sub(now))
+
+	case in == Never:
+		if p.cfg.Max > 0 {
+			return nil, model.Invalid("expires_in", "expires_in.never_not_allowed", ErrNeverNotAllowed,
+				map[string]any{"max": Format(p.cfg.Max)})
+		}
+		return nil, nil
+
+	case in != "":
+		d, ok := Presets[in]
+		if !ok {
+			var err error
+			if d, err = ParseDuration(in); err != nil || d <= 0 {
+				return nil, model.Invalid("expires_in", "expires_in.invalid", ErrInvalidExpiry, nil)
+			}
+		}
+		return p.check("expires_in", now.Add(d), d)
+
+	case required:
+		return nil, model.Invalid("expires_in", "field.required", ErrExpiryRequired, nil)
+	}
+
+	d := p.cfg.Default
+	if d <= 0 {
+		d = p.cfg.Max
+	}
+	if d <= 0 {
+		return nil, nil
+	}
+	expires := now.Add(d)
+	return &expires, nil
+}
+
+func (p Policy) check(field string, expires time.Time, d time.Duration) (*time.Time, error) {
+	if p.cfg.Min > 0 && d < p.cfg.Min {
+		return nil, model.Invalid(field, field+".too_short", ErrExpiryTooShort, map[string]any{"min": Format(p.cfg.Min)})
+	}
+	if p.cfg.Max > 0 && d > p.cfg.Max {
+		return nil, model.Invalid(field, field+".too_long", ErrExpiryTooLong, map[string]any{"max": Format(p.cfg.Max)})
+	}
+	return &expires, nil
+}
@@ -0,0 +1,111 @@
+package health
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+const (
+	StatusUp   = "up"
+	StatusDown = "down"
+
+	StatusOK       = "ok"
+	StatusDegraded = "degraded"
+	StatusFail     = "fail"
+)
+
+type CheckFunc func(ctx context.Context) error
+
+type Dependency struct {
+	Name     string
+	Critical bool
+	Check    CheckFunc
+}
+
+type DependencyStatus struct {
+	Status    string  `json:"status"`
+	Critical  bool    `json:"critical"`
+	LatencyMs float64 `json:"latency_ms"`
+	Error     string  `json:"error,omitempty"`
+}
+
+type Report struct {
+	Status       string                      `json:"status"`
+	Dependencies map[string]DependencyStatus `json:"dependencies"`
+}
+
+// Ready возвращает false, только если упала критичная зависимость.
+// Некритичные переводят отчет в degraded, но трафик на под продолжает идти.
+func (r Report) Ready() bool {
+	return r.Status != StatusFail
+}
+
+type Checker struct {
+	deps    []Dependency
+	timeout time.Duration
+}
+
+func NewChecker(timeout time.Duration) *Checker {
+	return &Checker{timeout: timeout}
+}
+
+func (c *Checker) Register(dep Dependency) {
+	c.deps = append(c.deps, dep)
+}
+
+// Check опрашивает все зависимости параллельно, каждую со своим таймаутом.
+func (c *Checker) Check(ctx context.Context) Report {
+	report := Report{
+		Status:       StatusOK,
+		Dependencies: make(map[string]DependencyStatus, len(c.deps)),
+	}
+
+	var (
+		mu sync.Mutex
+		wg sync.WaitGroup
+	)
+	for _, dep := range c.deps {
+		wg.Add(1)
+		go func(dep Dependency) {
+			defer wg.Done()
+			status := c.checkOne(ctx, dep)
+
+			mu.Lock()
+			report.Dependencies[dep.Name] = status
+			mu.Unlock()
+		}(dep)
+	}
+	wg.Wait()
+
+	for _, status := range report.Dependencies {
+		if status.Status == StatusUp {
+			continue
+		}
+		if status.Critical {
+			report.Status = StatusFail
+			break
+		}
+		report.Status = StatusDegraded
+	}
+
+	return report
+}
+
+func (c *Checker) checkOne(ctx context.Context, dep Dependency) DependencyStatus {
+	ctx, cancel := context.WithTimeout(ctx, c.timeout)
+	defer cancel()
+
+	start := time.Now()
+	err := dep.Check(ctx)
+	status := DependencyStatus{
+		Status:    StatusUp,
+		Critical:  dep.Critical,
+		LatencyMs: float64(time.Since(start).Microseconds()) / 1000,
+	}
+	if err != nil {
+		status.Status = StatusDown
+		status.Error = err.Error()
+	}
+	return status
+}
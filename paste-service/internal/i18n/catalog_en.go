@@ -0,0 +1,117 @@
+package i18n
+
+var english = map[string]string{
+	// request errors
+	"internal":                       "Internal server error",
+	"request.invalid":                "Invalid request",
+	"request.too_large":              "Request body is too large",
+	"request.too_large.detail":       "The request body must not exceed {limit} bytes",
+	"request.invalid_param":          "Invalid query parameter",
+	"request.unsupported_media_type": "Unsupported Content-Type",
+	"request.not_acceptable":         "No representation matches Accept: application/json, text/plain and text/html are supported",
+	"request.slug_required":          "Slug is required",
+	"request.edit_token_required":    "Edit token is required",
+	"request.file_required":          "No file in the file field",
+	"request.body_unreadable":        "Failed to read the request body",
+	"request.content_not_text":       "Content must be UTF-8 text",
+	"rate_limit.exceeded":            "Too many requests, try again later",
+	"admin.unauthorized":             "Administrator authorization required",
+	"admin.purge_target_required":    "Specify keys or all",
+
+	// pastes
+	"paste.invalid":               "Invalid paste data",
+	"paste.too_large":             "Paste content is too large",
+	"paste.not_found":             "Paste not found",
+	"paste.invalid_edit_token":    "Invalid edit token",
+	"paste.expired":               "Paste has expired",
+	"paste.locked":                "Paste is locked by a moderator",
+	"paste.precondition_required": "Send If-Match with the paste ETag or a version field",
+	"paste.version_mismatch":      "The paste has changed since it was read, fetch it again",
+	"paste.already_owned":         "Paste already has an owner",
+	"paste.secrets_detected":      "Secrets detected in paste content",
+	"paste.secrets_redacted":      "Detected secrets were replaced with [REDACTED]",
+	"paste.secrets_private":       "Secrets detected in the paste, it was saved as private",
+	"tagger.unavailable":          "Tagging service is unavailable",
+	"sluggen.unavailable":         "Slug generation service is unavailable",
+
+	// content policy
+	"policy.violation":      "Paste violates content rules",
+	"policy.max_lines":      "Paste has {lines} lines, the maximum is {max}",
+	"policy.denylist":       "Content matches a forbidden pattern",
+	"policy.blocked_domain": "Link to blocked domain {value}",
+	"policy.required_tag":   "Required tag {value} is missing",
+	"policy.forbidden_tag":  "Tag {value} is not allowed",
+
+	// quotas
+	"quota.exceeded":       "Quota exceeded",
+	"quota.pastes_per_day": "You can create at most {limit} pastes per day",
+	"quota.storage_bytes":  "Total paste size must not exceed {limit} bytes",
+	"quota.max_paste_size": "Paste size must not exceed {limit} bytes",
+
+	// reports
+	"report.invalid":      "Invalid report",
+	"report.not_found":    "Report not found",
+	"report.duplicate":    "You have already reported this paste",
+	"report.rate_limited": "Too many reports, try again later",
+
+	// share links
+	"share.invalid":   "Invalid share link parameters",
+	"share.not_found": "Share link not found",
+	"share.exhausted": "Share link is revoked, expired or out of views",
+	"share.disabled":  "Share links are not configured",
+
+	// accounts
+	"account.invalid":             "Invalid account data",
+	"account.username_taken":      "Username is already taken",
+	"account.invalid_credentials": "Invalid username or password",
+	"account.registration_closed": "Registration is closed",
+	"auth.required":               "Sign-in required",
+
+	// API keys
+	"apikey.invalid":            "Invalid API key",
+	"apikey.insufficient_scope": "API key lacks the required scope",
+	"apikey.invalid_request":    "Invalid API key parameters",
+	"apikey.not_found":          "API key not found",
+
+	// idempotency
+	"idempotency.invalid_key": "Invalid Idempotency-Key",
+	"idempotency.key_reused":  "Idempotency-Key was already used with a different request",
+	"idempotency.in_progress": "A request with this Idempotency-Key is still in progress",
+
+	// field errors
+	"field.required":               "This field is required",
+	"field.not_nullable":           "This field cannot be removed",
+	"field.invalid_type":           "Invalid value type",
+	"field.unknown":                "Unknown field",
+	"param.invalid":                "Invalid value for parameter {param}",
+	"content.empty":                "Paste content must not be empty",
+	"content.too_large":            "Paste content must not exceed {max} bytes",
+	"tags.too_many":                "At most {max} tags are allowed",
+	"tags.too_long":                "A tag must not be longer than {max} characters",
+	"visibility.invalid":           "Visibility must be public or private",
+	"title.too_long":               "Title must not exceed {max} characters",
+	"description.too_long":         "Description must not exceed {max} characters",
+	"filename.invalid":             "Filename must be at most {max} bytes, without directories or control characters",
+	"language.invalid":             "Language must be at most {max} characters of latin letters, digits and +#._-",
+	"username.invalid":             "Username must be 3 to 32 characters: latin letters, digits, '_', '-', '.'",
+	"password.too_short":           "Password must be at least {min} characters",
+	"password.too_long":            "Password must be at most {max} characters",
+	"reason.invalid":               "Unknown report reason",
+	"comment.too_long":             "Comment must not be longer than {max} characters",
+	"name.invalid":                 "Key name must be 1 to {max} characters",
+	"scopes.empty":                 "A key needs at least one scope",
+	"scopes.invalid":               "Unknown scope {value}",
+	"scopes.admin_forbidden":       "The admin scope can only be granted by an administrator",
+	"expires_in.not_positive":      "Expiry must be positive",
+	"expires_in.out_of_range":      "Expiry must be greater than 0 and at most {max}",
+	"expires_in.invalid":           "Expiry must be a duration (90m, 7d, 1w), a preset (hour, day, week, month, year) or never",
+	"expires_in.too_short":         "Expiry must be at least {min}",
+	"expires_in.too_long":          "Expiry must be at most {max}",
+	"expires_in.never_not_allowed": "Pastes must expire, the maximum is {max}",
+	"expires_at.past":              "Expiry time must be in the future",
+	"expires_at.too_short":         "Expiry time must be at least {min} from now",
+	"expires_at.too_long":          "Expiry time must be at most {max} from now",
+	"expires.conflict":             "Specify either expires_in or expires_at",
+	"max_views.negative":           "View limit must not be negative",
+	"action.invalid":               "Unknown decision {value}",
+}
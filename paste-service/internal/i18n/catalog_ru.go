@@ -0,0 +1,117 @@
+package i18n
+
+var russian = map[string]string{
+	// ошибки запроса
+	"internal":                       "Внутренняя ошибка сервера",
+	"request.invalid":                "Некорректный запрос",
+	"request.too_large":              "Тело запроса слишком большое",
+	"request.too_large.detail":       "Максимальный размер тела - {limit} байт",
+	"request.invalid_param":          "Некорректный параметр запроса",
+	"request.unsupported_media_type": "Неподдерживаемый Content-Type",
+	"request.not_acceptable":         "Нет представления для запрошенного Accept: поддерживаются application/json, text/plain и text/html",
+	"request.slug_required":          "Не указан slug",
+	"request.edit_token_required":    "Не указан токен редактирования",
+	"request.file_required":          "Не передан файл в поле file",
+	"request.body_unreadable":        "Не удалось прочитать тело запроса",
+	"request.content_not_text":       "Содержимое должно быть текстом в UTF-8",
+	"rate_limit.exceeded":            "Слишком много запросов, попробуйте позже",
+	"admin.unauthorized":             "Требуется авторизация администратора",
+	"admin.purge_target_required":    "Укажите keys или all",
+
+	// пасты
+	"paste.invalid":               "Некорректные данные пасты",
+	"paste.too_large":             "Содержимое пасты слишком большое",
+	"paste.not_found":             "Паста не найдена",
+	"paste.invalid_edit_token":    "Неверный токен редактирования",
+	"paste.expired":               "Срок действия пасты истек",
+	"paste.locked":                "Паста заблокирована модератором",
+	"paste.precondition_required": "Передайте If-Match с ETag пасты или поле version",
+	"paste.version_mismatch":      "Паста изменена с момента чтения, перечитайте ее",
+	"paste.already_owned":         "У пасты уже есть владелец",
+	"paste.secrets_detected":      "В содержимом пасты найдены секреты",
+	"paste.secrets_redacted":      "Найденные секреты заменены на [REDACTED]",
+	"paste.secrets_private":       "В пасте найдены секреты, она сохранена как приватная",
+	"tagger.unavailable":          "Сервис тэггирования недоступен",
+	"sluggen.unavailable":         "Сервис генерации slug недоступен",
+
+	// правила контента
+	"policy.violation":      "Паста нарушает правила контента",
+	"policy.max_lines":      "В пасте {lines} строк, максимум {max}",
+	"policy.denylist":       "Содержимое совпадает с запрещенным шаблоном",
+	"policy.blocked_domain": "Ссылка на заблокированный домен {value}",
+	"policy.required_tag":   "Отсутствует обязательный тег {value}",
+	"policy.forbidden_tag":  "Тег {value} запрещен",
+
+	// квоты
+	"quota.exceeded":       "Квота исчерпана",
+	"quota.pastes_per_day": "Можно создать не больше {limit} паст за сутки",
+	"quota.storage_bytes":  "Суммарный размер паст не может превышать {limit} байт",
+	"quota.max_paste_size": "Размер пасты не может превышать {limit} байт",
+
+	// жалобы
+	"report.invalid":      "Некорректная жалоба",
+	"report.not_found":    "Жалоба не найдена",
+	"report.duplicate":    "Вы уже отправили жалобу на эту пасту",
+	"report.rate_limited": "Слишком много жалоб, попробуйте позже",
+
+	// ссылки для чтения
+	"share.invalid":   "Некорректные параметры ссылки",
+	"share.not_found": "Ссылка не найдена",
+	"share.exhausted": "Ссылка отозвана, истекла или исчерпала лимит просмотров",
+	"share.disabled":  "Ссылки для чтения не настроены",
+
+	// учетные записи
+	"account.invalid":             "Некорректные данные учетной записи",
+	"account.username_taken":      "Имя пользователя занято",
+	"account.invalid_credentials": "Неверное имя пользователя или пароль",
+	"account.registration_closed": "Регистрация закрыта",
+	"auth.required":               "Требуется вход",
+
+	// API-ключи
+	"apikey.invalid":            "Недействительный API-ключ",
+	"apikey.insufficient_scope": "У ключа нет нужной области действия",
+	"apikey.invalid_request":    "Некорректные параметры ключа",
+	"apikey.not_found":          "Ключ не найден",
+
+	// идемпотентность
+	"idempotency.invalid_key": "Некорректный Idempotency-Key",
+	"idempotency.key_reused":  "Idempotency-Key уже использован с другим запросом",
+	"idempotency.in_progress": "Запрос с этим Idempotency-Key еще выполняется",
+
+	// ошибки отдельных полей
+	"field.required":               "Поле обязательно",
+	"field.not_nullable":           "Поле нельзя удалить",
+	"field.invalid_type":           "Некорректный тип значения",
+	"field.unknown":                "Неизвестное поле",
+	"param.invalid":                "Некорректное значение параметра {param}",
+	"content.empty":                "Содержимое пасты не может быть пустым",
+	"content.too_large":            "Содержимое пасты не может превышать {max} байт",
+	"tags.too_many":                "Можно указать не больше {max} тегов",
+	"tags.too_long":                "Тег не может быть длиннее {max} символов",
+	"visibility.invalid":           "Видимость должна быть public или private",
+	"title.too_long":               "Заголовок не может быть длиннее {max} символов",
+	"description.too_long":         "Описание не может быть длиннее {max} символов",
+	"filename.invalid":             "Имя файла - до {max} байт, без каталогов и управляющих символов",
+	"language.invalid":             "Язык - до {max} символов из латиницы, цифр и +#._-",
+	"username.invalid":             "Имя пользователя должно быть от 3 до 32 символов: латиница, цифры, '_', '-', '.'",
+	"password.too_short":           "Пароль должен быть не короче {min} символов",
+	"password.too_long":            "Пароль должен быть не длиннее {max} символов",
+	"reason.invalid":               "Неизвестная причина жалобы",
+	"comment.too_long":             "Комментарий не может быть длиннее {max} символов",
+	"name.invalid":                 "Имя ключа должно быть от 1 до {max} символов",
+	"scopes.empty":                 "У ключа должна быть хотя бы одна область действия",
+	"scopes.invalid":               "Неизвестная область действия {value}",
+	"scopes.admin_forbidden":       "Область admin выдается только администратором",
+	"expires_in.not_positive":      "Срок действия должен быть положительным",
+	"expires_in.out_of_range":      "Срок действия должен быть больше 0 и не больше {max}",
+	"expires_in.invalid":           "Срок действия: длительность (90m, 7d, 1w), пресет (hour, day, week, month, year) или never",
+	"expires_in.too_short":         "Срок действия не может быть меньше {min}",
+	"expires_in.too_long":          "Срок действия не может быть больше {max}",
+	"expires_in.never_not_allowed": "Бессрочные пасты запрещены, максимальный срок - {max}",
+	"expires_at.past":              "Момент истечения должен быть в будущем",
+	"expires_at.too_short":         "До истечения должно быть не меньше {min}",
+	"expires_at.too_long":          "До истечения должно быть не больше {max}",
+	"expires.conflict":             "Укажите либо expires_in, либо expires_at",
+	"max_views.negative":           "Лимит просмотров не может быть отрицательным",
+	"action.invalid":               "Неизвестное решение {value}",
+}
@@ -0,0 +1,91 @@
+// Package i18n подбирает язык по Accept-Language и переводит коды ошибок в сообщения.
+package i18n
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+type Lang string
+
+const (
+	Russian Lang = "ru"
+	English Lang = "en"
+
+	// язык по умолчанию, когда клиент не передал подходящий Accept-Language
+	Default = Russian
+)
+
+// Params подставляются в сообщение вместо {имя}.
+type Params map[string]any
+
+var catalogs = map[Lang]map[string]string{
+	Russian: russian,
+	English: english,
+}
+
+// Negotiate выбирает поддерживаемый язык с наибольшим q из заголовка Accept-Language.
+func Negotiate(acceptLanguage string) Lang {
+	type candidate struct {
+		lang Lang
+		q    float64
+	}
+
+	var candidates []candidate
+	for _, part := range strings.Split(acceptLanguage, ",") {
+		tag, params, _ := strings.Cut(strings.TrimSpace(part), ";")
+		if tag == "" {
+			continue
+		}
+
+		q := 1.0
+		if v, ok := strings.CutPrefix(strings.TrimSpace(params), "q="); ok {
+			parsed, err := strconv.ParseFloat(v, 64)
+			if err != nil {
+				continue
+			}
+			q = parsed
+		}
+		if q <= 0 {
+			continue
+		}
+
+		primary, _, _ := strings.Cut(strings.ToLower(tag), "-")
+		if primary == "*" {
+			candidates = append(candidates, candidate{Default, q})
+			continue
+		}
+		if _, ok := catalogs[Lang(primary)]; ok {
+			candidates = append(candidates, candidate{Lang(primary), q})
+		}
+	}
+
+	if len(candidates) == 0 {
+		return Default
+	}
+	// при равных q побеждает тот, что указан раньше
+	sort.SliceStable(candidates, func(i, j int) bool { return candidates[i].q > candidates[j].q })
+	return candidates[0].lang
+}
+
+// Message возвращает сообщение для кода на нужном языке. Если перевода нет,
+// берется язык по умолчанию, если нет и его - сам код.
+func Message(lang Lang, code string, params Params) string {
+	msg, ok := catalogs[lang][code]
+	if !ok {
+		if msg, ok = catalogs[Default][code]; !ok {
+			return code
+		}
+	}
+	if len(params) == 0 {
+		return msg
+	}
+
+	replacements := make([]string, 0, len(params)*2)
+	for name, value := range params {
+		replacements = append(replacements, "{"+name+"}", fmt.Sprint(value))
+	}
+	return strings.NewReplacer(replacements...).Replace(msg)
+}
@@ -0,0 +1,218 @@
+// Package langdetect угадывает язык пасты для подсветки и листингов: сначала по имени
+// файла, затем по шебангу и характерным конструкциям в начале содержимого.
+// Ошибиться не страшно, клиент всегда может указать язык явно.
+package langdetect
+
+import (
+	"encoding/json"
+	"path"
+	"regexp"
+	"strings"
+)
+
+// sniffSize - сколько содержимого смотрят эвристики; больших логов это не замедляет.
+const sniffSize = 4096
+
+var byExtension = map[string]string{
+	".go":    "go",
+	".py":    "python",
+	".js":    "javascript",
+	".mjs":   "javascript",
+	".cjs":   "javascript",
+	".jsx":   "javascript",
+	".ts":    "typescript",
+	".tsx":   "typescript",
+	".rb":    "ruby",
+	".rs":    "rust",
+	".java":  "java",
+	".kt":    "kotlin",
+	".kts":   "kotlin",
+	".swift": "swift",
+	".c":     "c",
+	".h":     "c",
+	".cc":    "cpp",
+	".cpp":   "cpp",
+	".cxx":   "cpp",
+	".hpp":   "cpp",
+	".cs":    "csharp",
+	".php":   "php",
+	".sh":    "bash",
+	".bash":  "bash",
+	".zsh":   "bash",
+	".ps1":   "powershell",
+	".sql":   "sql",
+	".json":  "json",
+	".yaml":  "yaml",
+	".yml":   "yaml",
+	".toml":  "toml",
+	".ini":   "ini",
+	".xml":   "xml",
+	".html":  "html",
+	".htm":   "html",
+	".css":   "css",
+	".scss":  "scss",
+	".md":    "markdown",
+	".diff":  "diff",
+	".patch": "diff",
+	".lua":   "lua",
+	".pl":    "perl",
+	".proto": "protobuf",
+	".tf":    "hcl",
+	".hcl":   "hcl",
+	".log":   "text",
+	".txt":   "text",
+}
+
+var byName = map[string]string{
+	"dockerfile":  "dockerfile",
+	"makefile":    "makefile",
+	"gnumakefile": "makefile",
+	"go.mod":      "gomod",
+	"jenkinsfile": "groovy",
+}
+
+// интерпретаторы из шебанга
+var byInterpreter = map[string]string{
+	"sh":      "bash",
+	"bash":    "bash",
+	"zsh":     "bash",
+	"python":  "python",
+	"python3": "python",
+	"node":    "javascript",
+	"ruby":    "ruby",
+	"perl":    "perl",
+	"php":     "php",
+	"lua":     "lua",
+}
+
+var aliases = map[string]string{
+	"golang":     "go",
+	"py":         "python",
+	"python3":    "python",
+	"js":         "javascript",
+	"node":       "javascript",
+	"ts":         "typescript",
+	"sh":         "bash",
+	"shell":      "bash",
+	"zsh":        "bash",
+	"rb":         "ruby",
+	"rs":         "rust",
+	"c++":        "cpp",
+	"cs":         "csharp",
+	"c#":         "csharp",
+	"yml":        "yaml",
+	"md":         "markdown",
+	"patch":      "diff",
+	"plain":      "text",
+	"plaintext":  "text",
+	"txt":        "text",
+	"docker":     "dockerfile",
+	"make":       "makefile",
+	"terraform":  "hcl",
+	"postgresql": "sql",
+}
+
+var jsonPrefixRe = regexp.MustCompile(`^(\{\s*"[^"\n]*"\s*:|\[\s*[\{\["])`)
+
+// сигнатуры проверяются по порядку, первая совпавшая побеждает
+var signatures = []struct {
+	lang string
+	re   *regexp.Regexp
+}{
+	{"php", regexp.MustCompile(`^\s*<\?php`)},
+	{"html", regexp.MustCompile(`(?i)^\s*(<!doctype html|<html)`)},
+	{"xml", regexp.MustCompile(`^\s*<\?xml`)},
+	{"diff", regexp.MustCompile(`(?m)^(diff --git |--- a/|\+\+\+ b/|@@ -\d+(,\d+)? \+\d+(,\d+)? @@)`)},
+	{"go", regexp.MustCompile(`(?m)^package \w+\s*$[\s\S]*^(func|import|type|var|const)\b`)},
+	{"dockerfile", regexp.MustCompile(`(?im)^FROM\s+\S+[\s\S]*^(RUN|COPY|CMD|ENTRYPOINT)\s`)},
+	{"python", regexp.MustCompile(`(?m)^(def \w+\(.*\):|class \w+(\(.*\))?:|from [\w.]+ import |import \w+$|if __name__ == ['"]__main__['"]:)`)},
+	{"rust", regexp.MustCompile(`(?m)^\s*(fn \w+|use \w+::|impl\b|pub (fn|struct|enum)\b)`)},
+	{"java", regexp.MustCompile(`(?m)^\s*(public |private )?(class|interface) \w+[\s\S]*\b(public|private|protected) (static )?\w+`)},
+	{"c", regexp.MustCompile(`(?m)^#include\s*[<"]`)},
+	{"javascript", regexp.MustCompile(`(?m)^\s*(const|let) \w+ = require\(|^\s*import .* from ['"]|^\s*export (default|function|const)\b|console\.log\(`)},
+	{"sql", regexp.MustCompile(`(?i)^\s*(select\s[\s\S]+\sfrom\s|insert\s+into\s|update\s+\w+\s+set\s|create\s+(table|index|view)\s|alter\s+table\s)`)},
+	// ключи в нижнем регистре, чтобы не принимать за YAML логи вида "Error: ..."
+	{"yaml", regexp.MustCompile(`^(---\s*\n)?[a-z_][\w.-]*:(\s|$)[\s\S]*\n[a-z_][\w.-]*:(\s|$)`)},
+}
+
+// Detect возвращает язык по имени файла, а если по нему не понять - по содержимому.
+// Пустая строка - язык не определен.
+func Detect(filename, content string) string {
+	if lang := FromFilename(filename); lang != "" {
+		return lang
+	}
+	return FromContent(content)
+}
+
+func FromFilename(filename string) string {
+	if filename == "" {
+		return ""
+	}
+	base := strings.ToLower(path.Base(filename))
+	if lang, ok := byName[base]; ok {
+		return lang
+	}
+	if strings.HasPrefix(base, "dockerfile.") {
+		return "dockerfile"
+	}
+	return byExtension[path.Ext(base)]
+}
+
+func FromContent(content string) string {
+	if len(content) > sniffSize {
+		content = content[:sniffSize]
+	}
+	if strings.TrimSpace(content) == "" {
+		return ""
+	}
+
+	if strings.HasPrefix(content, "#!") {
+		if lang := fromShebang(content); lang != "" {
+			return lang
+		}
+	}
+
+	trimmed := strings.TrimSpace(content)
+	if (strings.HasPrefix(trimmed, "{") || strings.HasPrefix(trimmed, "[")) && json.Valid([]byte(trimmed)) {
+		return "json"
+	}
+	// большой JSON обрезан на sniffSize и целиком не разбирается
+	if jsonPrefixRe.MatchString(trimmed) {
+		return "json"
+	}
+
+	for _, sig := range signatures {
+		if sig.re.MatchString(content) {
+			return sig.lang
+		}
+	}
+	return ""
+}
+
+// fromShebang разбирает #!/usr/bin/env python3 и #!/bin/bash -e.
+func fromShebang(content string) string {
+	line, _, _ := strings.Cut(content[2:], "\n")
+	fields := strings.Fields(line)
+	if len(fields) == 0 {
+		return ""
+	}
+	interpreter := path.Base(fields[0])
+	if interpreter == "env" && len(fields) > 1 {
+		interpreter = fields[1]
+	}
+	// python3.12 -> python3
+	if i := strings.IndexByte(interpreter, '.'); i > 0 {
+		interpreter = interpreter[:i]
+	}
+	return byInterpreter[interpreter]
+}
+
+// Normalize приводит язык от клиента к каноническому имени: Golang -> go, yml -> yaml.
+// Неизвестные языки остаются как есть в нижнем регистре.
+func Normalize(lang string) string {
+	lang = strings.ToLower(strings.TrimSpace(lang))
+	if canonical, ok := aliases[lang]; ok {
+		return canonical
+	}
+	return lang
+}
@@ -0,0 +1,101 @@
+package logging
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+// GormLogger пишет логи gorm в тот же slog, что и остальной сервис.
+// Значения параметров запросов никогда не логируются: там содержимое паст и хэши токенов.
+type GormLogger struct {
+	logger        *slog.Logger
+	level         logger.LogLevel
+	slowThreshold time.Duration
+}
+
+func NewGormLogger(l *slog.Logger, level slog.Level, slowThreshold time.Duration) *GormLogger {
+	return &GormLogger{
+		logger:        l.With(slog.String("component", "gorm")),
+		level:         gormLevel(level),
+		slowThreshold: slowThreshold,
+	}
+}
+
+func gormLevel(level slog.Level) logger.LogLevel {
+	switch {
+	case level <= slog.LevelDebug:
+		return logger.Info
+	case level <= slog.LevelWarn:
+		return logger.Warn
+	default:
+		return logger.Error
+	}
+}
+
+func (l *GormLogger) LogMode(level logger.LogLevel) logger.Interface {
+	clone := *l
+	clone.level = level
+	return &clone
+}
+
+func (l *GormLogger) Info(ctx context.Context, msg string, args ...interface{}) {
+	if l.level >= logger.Info {
+		l.logger.InfoContext(ctx, fmt.Sprintf(msg, args...))
+	}
+}
+
+func (l *GormLogger) Warn(ctx context.Context, msg string, args ...interface{}) {
+	if l.level >= logger.Warn {
+		l.logger.WarnContext(ctx, fmt.Sprintf(msg, args...))
+	}
+}
+
+func (l *GormLogger) Error(ctx context.Context, msg string, args ...interface{}) {
+	if l.level >= logger.Error {
+		l.logger.ErrorContext(ctx, fmt.Sprintf(msg, args...))
+	}
+}
+
+func (l *GormLogger) Trace(ctx context.Context, begin time.Time, fc func() (string, int64), err error) {
+	if l.level <= logger.Silent {
+		return
+	}
+
+	elapsed := time.Since(begin)
+	switch {
+	case err != nil && l.level >= logger.Error && !errors.Is(err, gorm.ErrRecordNotFound):
+		sql, rows := fc()
+		l.logger.ErrorContext(ctx, "sql query failed",
+			slog.String("sql", sql),
+			slog.Int64("rows", rows),
+			slog.Duration("elapsed", elapsed),
+			slog.String("error", err.Error()),
+		)
+	case l.slowThreshold > 0 && elapsed > l.slowThreshold && l.level >= logger.Warn:
+		sql, rows := fc()
+		l.logger.WarnContext(ctx, "slow sql query",
+			slog.String("sql", sql),
+			slog.Int64("rows", rows),
+			slog.Duration("elapsed", elapsed),
+			slog.Duration("threshold", l.slowThreshold),
+		)
+	case l.level >= logger.Info:
+		sql, rows := fc()
+		l.logger.DebugContext(ctx, "sql query",
+			slog.String("sql", sql),
+			slog.Int64("rows", rows),
+			slog.Duration("elapsed", elapsed),
+		)
+	}
+}
+
+// ParamsFilter отдает gorm запрос без значений, поэтому в sql попадают только плейсхолдеры.
+func (l *GormLogger) ParamsFilter(ctx context.Context, sql string, params ...interface{}) (string, []interface{}) {
+	return sql, nil
+}
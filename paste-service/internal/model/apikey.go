@@ -0,0 +1,75 @@
+package model
+
+import (
+	"errors"
+	"time"
+)
+
+const (
+	ScopePasteCreate      = "paste:create"
+	ScopePasteReadPrivate = "paste:read-private" // приватные пасты владельца ключа
+	ScopeAdmin            = "admin"
+)
+
+const MaxAPIKeyNameLength = 100
+
+var (
+	ErrInvalidScope      = errors.New("неизвестная область действия ключа")
+	ErrEmptyScopes       = errors.New("у ключа должна быть хотя бы одна область действия")
+	ErrAPIKeyNameInvalid = errors.New("имя ключа должно быть от 1 до 100 символов")
+)
+
+// APIKey - ключ для программного доступа, передается в заголовке Authorization.
+// Сам ключ не храним: только sha256 и префикс, чтобы ключ можно было узнать в списке.
+type APIKey struct {
+	ID         string   `gorm:"primaryKey"`    // uuid v7
+	UserID     *string  `gorm:"size:36;index"` // nil у ключей, выданных администратором
+	Name       string   `gorm:"size:100;not null"`
+	Prefix     string   `gorm:"size:16;not null"`
+	KeyHash    string   `gorm:"size:64;not null;uniqueIndex"`
+	Scopes     []string `gorm:"type:jsonb;serializer:json;not null;default:'[]'"`
+	ExpiresAt  *time.Time
+	LastUsedAt *time.Time
+	Revoked    bool `gorm:"default:false;not null"`
+	RevokedAt  *time.Time
+	CreatedBy  string    `gorm:"size:100"`
+	CreatedAt  time.Time `gorm:"autoCreateTime"`
+}
+
+func IsValidScope(scope string) bool {
+	switch scope {
+	case ScopePasteCreate, ScopePasteReadPrivate, ScopeAdmin:
+		return true
+	}
+	return false
+}
+
+func (k *APIKey) Validate() error {
+	v := &ValidationError{}
+	if len(k.Name) == 0 || len(k.Name) > MaxAPIKeyNameLength {
+		v.Add("name", "name.invalid", ErrAPIKeyNameInvalid, map[string]any{"max": MaxAPIKeyNameLength})
+	}
+	if len(k.Scopes) == 0 {
+		v.Add("scopes", "scopes.empty", ErrEmptyScopes, nil)
+	}
+	for _, scope := range k.Scopes {
+		if !IsValidScope(scope) {
+			v.Add("scopes", "scopes.invalid", ErrInvalidScope, map[string]any{"value": scope})
+		}
+	}
+	return v.OrNil()
+}
+
+func (k *APIKey) HasScope(scope string) bool {
+	for _, s := range k.Scopes {
+		if s == scope {
+			return true
+		}
+	}
+	return false
+}
+
+// IsActive - ключ не отозван и не истек.
+func (k *APIKey) IsActive() bool {
+	return !k.Revoked && (k.ExpiresAt == nil || time.Now().Before(*k.ExpiresAt))
+}
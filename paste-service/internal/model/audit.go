@@ -0,0 +1,15 @@
+package model
+
+import "time"
+
+// AuditEntry - запись журнала действий администратора.
+type AuditEntry struct {
+	ID        string    `gorm:"primaryKey"` // uuid v7
+	Actor     string    `gorm:"size:100;not null;index"`
+	Action    string    `gorm:"size:50;not null;index"`
+	Target    string    `gorm:"size:200"`
+	Details   string    `gorm:"type:jsonb;default:'{}'"`
+	RemoteIP  string    `gorm:"size:64"`
+	RequestID string    `gorm:"size:128"`
+	CreatedAt time.Time `gorm:"autoCreateTime;index"`
+}
@@ -0,0 +1,26 @@
+package model
+
+import "time"
+
+// IdempotencyRecord - результат запроса с заголовком Idempotency-Key.
+// ID - хэш от клиента и ключа, сам ключ не храним. Ответ зашифрован ключом,
+// выведенным из Idempotency-Key, поэтому токен редактирования в нем не читается по дампу БД.
+type IdempotencyRecord struct {
+	ID          string `gorm:"primaryKey;size:64"`
+	RequestHash string `gorm:"size:64;not null"`
+	// 0 - запрос еще выполняется
+	StatusCode  int       `gorm:"not null;default:0"`
+	ContentType string    `gorm:"size:100"`
+	Headers     string    `gorm:"type:jsonb;default:'{}'"` // ETag, Location и т.п. без секретов
+	Response    []byte    `gorm:"type:bytea"`
+	ExpiresAt   time.Time `gorm:"not null;index"`
+	CreatedAt   time.Time `gorm:"autoCreateTime"`
+}
+
+func (r *IdempotencyRecord) HasExpired() bool {
+	return time.Now().After(r.ExpiresAt)
+}
+
+func (r *IdempotencyRecord) IsPending() bool {
+	return r.StatusCode == 0
+}
@@ -2,22 +2,46 @@ package model
 
 import (
 	"errors"
+	"fmt"
+	"regexp"
+	"strings"
 	"time"
+	"unicode"
+	"unicode/utf8"
 )
 
 const (
-	MaxContentSize = 1024 * 1024 // 1MB
+	// жесткий потолок хранения; рабочие лимиты задаются на HTTP-уровне
+	// через SERVER_MAXREQUESTSIZE и SERVER_MAXUPLOADSIZE
+	MaxContentSize = 32 * 1024 * 1024 // 32MB
 	MaxTagsCount   = 10
 	MaxTagLength   = 50
+
+	MaxTitleLength       = 200 // в символах, как и описание
+	MaxDescriptionLength = 2000
+	MaxFilenameLength    = 255 // в байтах, как в большинстве файловых систем
+	MaxLanguageLength    = 32
+)
+
+const (
+	VisibilityPublic  = "public"
+	VisibilityPrivate = "private" // не попадает в листинги, читается только владельцем, с токеном или по ссылке
 )
 
 var (
-	ErrContentTooLarge = errors.New("содержимое пасты слишком большое")
-	ErrTooManyTags     = errors.New("слишком много тегов")
-	ErrTagTooLong      = errors.New("тег слишком длинный")
-	ErrEmptyContent    = errors.New("содержимое пасты не может быть пустым")
+	ErrContentTooLarge    = errors.New("содержимое пасты слишком большое")
+	ErrTooManyTags        = errors.New("слишком много тегов")
+	ErrTagTooLong         = errors.New("тег слишком длинный")
+	ErrEmptyContent       = errors.New("содержимое пасты не может быть пустым")
+	ErrInvalidVisibility  = errors.New("некорректная видимость пасты")
+	ErrTitleTooLong       = errors.New("заголовок слишком длинный")
+	ErrDescriptionTooLong = errors.New("описание слишком длинное")
+	ErrInvalidFilename    = errors.New("некорректное имя файла")
+	ErrInvalidLanguage    = errors.New("некорректный язык")
 )
 
+var languageRe = regexp.MustCompile(`^[a-z0-9][a-z0-9+#._-]*$`)
+
 type Paste struct {
 	ID         string    `gorm:"primaryKey"` // uuid v7
 	Slug       string    `gorm:"uniqueIndex;size:50;not null"`
@@ -29,28 +53,92 @@ type Paste struct {
 	ViewCount  int       `gorm:"default:0"`
 	LastViewed *time.Time
 	Expires    *time.Time
+	Locked     bool    `gorm:"default:false;not null"` // заблокирована админом, редактирование запрещено
+	Hidden     bool    `gorm:"default:false;not null"` // скрыта из листингов по жалобам
+	Visibility string  `gorm:"size:20;not null;default:'public';index"`
+	OwnerID    *string `gorm:"size:36;index"`  // nil у анонимных паст
+	APIKeyID   *string `gorm:"size:36;index"`  // ключ, которым создана паста
+	Creator    string  `gorm:"size:100;index"` // субъект квоты: key:<id>, user:<id> или ip:<hash>
+	// растет при каждом изменении пасты, кроме просмотров; отдается клиентам как ETag
+	Version int64 `gorm:"not null;default:1"`
+
+	Title       string `gorm:"size:200"`
+	Description string `gorm:"type:text"`
+	Filename    string `gorm:"size:255"`
+	Language    string `gorm:"size:32;index"`
+	// заголовок и язык угаданы сервисом, а не заданы клиентом: при смене содержимого
+	// или имени файла их надо угадать заново
+	TitleInferred    bool `gorm:"default:false;not null"`
+	LanguageInferred bool `gorm:"default:false;not null"`
 }
 
 func (p *Paste) Validate() error {
+	v := &ValidationError{}
+
 	if len(p.Content) == 0 {
-		return ErrEmptyContent
+		v.Add("content", "content.empty", ErrEmptyContent, nil)
 	}
 
 	if len(p.Content) > MaxContentSize {
-		return ErrContentTooLarge
+		v.Add("content", "content.too_large", ErrContentTooLarge, map[string]any{"max": MaxContentSize})
 	}
 
 	if len(p.Tags) > MaxTagsCount {
-		return ErrTooManyTags
+		v.Add("tags", "tags.too_many", ErrTooManyTags, map[string]any{"max": MaxTagsCount})
 	}
 
-	for _, tag := range p.Tags {
+	for i, tag := range p.Tags {
 		if len(tag) > MaxTagLength {
-			return ErrTagTooLong
+			v.Add(fmt.Sprintf("tags[%d]", i), "tags.too_long", ErrTagTooLong, map[string]any{"max": MaxTagLength})
+		}
+	}
+
+	if !IsValidVisibility(p.Visibility) {
+		v.Add("visibility", "visibility.invalid", ErrInvalidVisibility, nil)
+	}
+
+	if utf8.RuneCountInString(p.Title) > MaxTitleLength {
+		v.Add("title", "title.too_long", ErrTitleTooLong, map[string]any{"max": MaxTitleLength})
+	}
+
+	if utf8.RuneCountInString(p.Description) > MaxDescriptionLength {
+		v.Add("description", "description.too_long", ErrDescriptionTooLong, map[string]any{"max": MaxDescriptionLength})
+	}
+
+	if p.Filename != "" && !IsValidFilename(p.Filename) {
+		v.Add("filename", "filename.invalid", ErrInvalidFilename, map[string]any{"max": MaxFilenameLength})
+	}
+
+	if p.Language != "" && (len(p.Language) > MaxLanguageLength || !languageRe.MatchString(p.Language)) {
+		v.Add("language", "language.invalid", ErrInvalidLanguage, map[string]any{"max": MaxLanguageLength})
+	}
+
+	return v.OrNil()
+}
+
+// IsValidFilename пропускает только имя без каталогов: оно уходит в Content-Disposition.
+func IsValidFilename(name string) bool {
+	if len(name) > MaxFilenameLength || name == "." || name == ".." || strings.ContainsAny(name, `/\`) {
+		return false
+	}
+	for _, r := range name {
+		if unicode.IsControl(r) || r == utf8.RuneError {
+			return false
 		}
 	}
+	return true
+}
+
+func IsValidVisibility(visibility string) bool {
+	return visibility == VisibilityPublic || visibility == VisibilityPrivate
+}
+
+func (p *Paste) IsPrivate() bool {
+	return p.Visibility == VisibilityPrivate
+}
 
-	return nil
+func (p *Paste) IsOwnedBy(userID string) bool {
+	return userID != "" && p.OwnerID != nil && *p.OwnerID == userID
 }
 
 func (p *Paste) HasExpired() bool {
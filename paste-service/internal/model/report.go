@@ -0,0 +1,63 @@
+package model
+
+import (
+	"errors"
+	"time"
+)
+
+const MaxReportCommentLength = 1000
+
+const (
+	ReportReasonSpam         = "spam"
+	ReportReasonMalware      = "malware"
+	ReportReasonPhishing     = "phishing"
+	ReportReasonIllegal      = "illegal"
+	ReportReasonPersonalData = "personal_data"
+	ReportReasonOther        = "other"
+)
+
+const (
+	ReportStatusOpen      = "open"
+	ReportStatusResolved  = "resolved"
+	ReportStatusDismissed = "dismissed"
+)
+
+var (
+	ErrInvalidReportReason  = errors.New("неизвестная причина жалобы")
+	ErrReportCommentTooLong = errors.New("комментарий к жалобе слишком длинный")
+)
+
+// Report - жалоба на пасту. Открытая жалоба от клиента на пасту может быть только одна;
+// после решения по ней клиент может пожаловаться снова.
+type Report struct {
+	ID           string `gorm:"primaryKey"` // uuid v7
+	PasteSlug    string `gorm:"size:50;not null;uniqueIndex:idx_report_paste_reporter_open,where:status = 'open';index"`
+	Reason       string `gorm:"size:30;not null"`
+	Comment      string `gorm:"size:1000"`
+	ReporterHash string `gorm:"size:64;not null;uniqueIndex:idx_report_paste_reporter_open"` // sha256 от IP, сам IP не храним
+	Status       string `gorm:"size:20;not null;default:'open';index"`
+	Resolution   string `gorm:"size:30"`
+	ResolvedBy   string `gorm:"size:100"`
+	ResolvedAt   *time.Time
+	CreatedAt    time.Time `gorm:"autoCreateTime;index"`
+}
+
+func IsValidReportReason(reason string) bool {
+	switch reason {
+	case ReportReasonSpam, ReportReasonMalware, ReportReasonPhishing,
+		ReportReasonIllegal, ReportReasonPersonalData, ReportReasonOther:
+		return true
+	}
+	return false
+}
+
+func (r *Report) Validate() error {
+	v := &ValidationError{}
+	if !IsValidReportReason(r.Reason) {
+		v.Add("reason", "reason.invalid", ErrInvalidReportReason, nil)
+	}
+	if len(r.Comment) > MaxReportCommentLength {
+		v.Add("comment", "comment.too_long", ErrReportCommentTooLong, map[string]any{"max": MaxReportCommentLength})
+	}
+	return v.OrNil()
+}
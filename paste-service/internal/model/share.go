@@ -0,0 +1,16 @@
+package model
+
+import "time"
+
+// ShareLink - выданная ссылка на чтение пасты. Подпись живет в самой ссылке,
+// здесь хранится состояние: счетчик просмотров и отзыв.
+type ShareLink struct {
+	ID        string    `gorm:"primaryKey"` // uuid v7
+	PasteSlug string    `gorm:"size:50;not null;index"`
+	ExpiresAt time.Time `gorm:"not null"`
+	MaxViews  int       `gorm:"default:0;not null"` // 0 - без ограничения
+	Views     int       `gorm:"default:0;not null"`
+	Revoked   bool      `gorm:"default:false;not null"`
+	RevokedAt *time.Time
+	CreatedAt time.Time `gorm:"autoCreateTime"`
+}
@@ -0,0 +1,55 @@
+package model
+
+import (
+	"errors"
+	"regexp"
+	"time"
+)
+
+const (
+	MinPasswordLength = 8
+	MaxPasswordLength = 72 // больше bcrypt все равно не учитывает
+)
+
+var (
+	ErrInvalidUsername  = errors.New("имя пользователя должно быть от 3 до 32 символов: латиница, цифры, '_', '-', '.'")
+	ErrPasswordTooShort = errors.New("пароль слишком короткий")
+	ErrPasswordTooLong  = errors.New("пароль слишком длинный")
+)
+
+var usernamePattern = regexp.MustCompile(`^[a-z0-9][a-z0-9_.-]{2,31}$`)
+
+// User - локальная учетная запись. Имя хранится в нижнем регистре.
+type User struct {
+	ID           string    `gorm:"primaryKey"` // uuid v7
+	Username     string    `gorm:"uniqueIndex;size:32;not null"`
+	PasswordHash string    `gorm:"size:100;not null"` // bcrypt
+	CreatedAt    time.Time `gorm:"autoCreateTime"`
+	UpdatedAt    time.Time `gorm:"autoUpdateTime"`
+}
+
+// Session - сессия входа. В ID лежит sha256 от cookie, сам токен не храним.
+type Session struct {
+	ID        string    `gorm:"primaryKey;size:64"`
+	UserID    string    `gorm:"size:36;not null;index"`
+	ExpiresAt time.Time `gorm:"not null;index"`
+	CreatedAt time.Time `gorm:"autoCreateTime"`
+}
+
+func IsValidUsername(username string) bool {
+	return usernamePattern.MatchString(username)
+}
+
+func ValidatePassword(password string) error {
+	if len(password) < MinPasswordLength {
+		return Invalid("password", "password.too_short", ErrPasswordTooShort, map[string]any{"min": MinPasswordLength})
+	}
+	if len(password) > MaxPasswordLength {
+		return Invalid("password", "password.too_long", ErrPasswordTooLong, map[string]any{"max": MaxPasswordLength})
+	}
+	return nil
+}
+
+func (s *Session) HasExpired() bool {
+	return time.Now().After(s.ExpiresAt)
+}
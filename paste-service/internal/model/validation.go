@@ -0,0 +1,61 @@
+package model
+
+import "strings"
+
+// FieldError - ошибка проверки одного поля. Code - стабильный код для клиентов,
+// по нему API подбирает сообщение на языке клиента. Err - сентинел, по которому
+// ошибку можно узнать через errors.Is.
+type FieldError struct {
+	Field  string
+	Code   string
+	Params map[string]any
+	Err    error
+}
+
+func (e *FieldError) Error() string {
+	return e.Field + ": " + e.Err.Error()
+}
+
+func (e *FieldError) Unwrap() error {
+	return e.Err
+}
+
+// ValidationError собирает все ошибки полей, а не только первую.
+type ValidationError struct {
+	Fields []*FieldError
+}
+
+// Invalid - ошибка проверки с одним полем.
+func Invalid(field, code string, err error, params map[string]any) *ValidationError {
+	v := &ValidationError{}
+	v.Add(field, code, err, params)
+	return v
+}
+
+func (e *ValidationError) Add(field, code string, err error, params map[string]any) {
+	e.Fields = append(e.Fields, &FieldError{Field: field, Code: code, Params: params, Err: err})
+}
+
+// OrNil возвращает nil, если ошибок не набралось, чтобы не получить ненулевой интерфейс error.
+func (e *ValidationError) OrNil() error {
+	if len(e.Fields) == 0 {
+		return nil
+	}
+	return e
+}
+
+func (e *ValidationError) Error() string {
+	msgs := make([]string, 0, len(e.Fields))
+	for _, f := range e.Fields {
+		msgs = append(msgs, f.Error())
+	}
+	return strings.Join(msgs, "; ")
+}
+
+func (e *ValidationError) Unwrap() []error {
+	errs := make([]error, 0, len(e.Fields))
+	for _, f := range e.Fields {
+		errs = append(errs, f)
+	}
+	return errs
+}
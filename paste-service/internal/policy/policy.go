@@ -0,0 +1,148 @@
+package policy
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+
+	"paste-service/config"
+)
+
+var ErrViolation = errors.New("паста нарушает правила контента")
+
+const (
+	CodeMaxLines      = "policy.max_lines"
+	CodeDenylist      = "policy.denylist"
+	CodeBlockedDomain = "policy.blocked_domain"
+	CodeRequiredTag   = "policy.required_tag"
+	CodeForbiddenTag  = "policy.forbidden_tag"
+)
+
+// Input - то, что проверяют правила. Теги уже окончательные, после автотэггинга.
+type Input struct {
+	Content string
+	Tags    []string
+}
+
+// Violation - отказ конкретного правила. Реализует error, чтобы пройти через сервис как есть.
+type Violation struct {
+	Rule    string `json:"rule"`
+	Code    string `json:"code"`
+	Message string `json:"message"`
+	Line    int    `json:"line,omitempty"`
+	Value   string `json:"value,omitempty"`
+	// подстановки для перевода сообщения по Code
+	Params map[string]any `json:"-"`
+}
+
+func (v *Violation) Error() string {
+	return fmt.Sprintf("%v: %s", ErrViolation, v.Message)
+}
+
+func (v *Violation) Unwrap() error {
+	return ErrViolation
+}
+
+type Rule interface {
+	Name() string
+	// Check возвращает nil, если содержимое проходит правило
+	Check(in Input) *Violation
+}
+
+type Pipeline struct {
+	rules []Rule
+}
+
+func NewPipeline(rules ...Rule) *Pipeline {
+	return &Pipeline{rules: rules}
+}
+
+// Add дописывает правило в конец пайплайна, так подключаются правила вне пакета.
+func (p *Pipeline) Add(rule Rule) {
+	p.rules = append(p.rules, rule)
+}
+
+// Evaluate прогоняет правила по порядку и останавливается на первом отказе.
+func (p *Pipeline) Evaluate(in Input) error {
+	for _, rule := range p.rules {
+		if v := rule.Check(in); v != nil {
+			return v
+		}
+	}
+	return nil
+}
+
+// NewFromConfig собирает пайплайн из встроенных правил. Правила с пустыми
+// настройками не добавляются.
+func NewFromConfig(cfg config.PolicyConfig) (*Pipeline, error) {
+	p := NewPipeline()
+
+	if cfg.MaxLines > 0 {
+		p.Add(MaxLines(cfg.MaxLines))
+	}
+
+	patterns := append([]string(nil), cfg.Denylist...)
+	if cfg.DenylistFile != "" {
+		filePatterns, err := readLines(cfg.DenylistFile)
+		if err != nil {
+			return nil, fmt.Errorf("ошибка чтения %s: %w", cfg.DenylistFile, err)
+		}
+		patterns = append(patterns, filePatterns...)
+	}
+	if len(patterns) > 0 {
+		rule, err := NewRegexDenylist(patterns)
+		if err != nil {
+			return nil, err
+		}
+		p.Add(rule)
+	}
+
+	if len(cfg.BlockedDomains) > 0 {
+		p.Add(NewDomainBlocklist(cfg.BlockedDomains))
+	}
+	if len(cfg.RequiredTags) > 0 {
+		p.Add(RequiredTags(normalizeList(cfg.RequiredTags)))
+	}
+	if len(cfg.ForbiddenTags) > 0 {
+		p.Add(ForbiddenTags(normalizeList(cfg.ForbiddenTags)))
+	}
+
+	return p, nil
+}
+
+// readLines читает непустые строки файла, строки с # считаются комментариями.
+func readLines(path string) ([]string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var lines []string
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		lines = append(lines, line)
+	}
+	return lines, scanner.Err()
+}
+
+func normalizeList(values []string) []string {
+	result := make([]string, 0, len(values))
+	for _, v := range values {
+		v = strings.ToLower(strings.TrimSpace(v))
+		if v != "" {
+			result = append(result, v)
+		}
+	}
+	return result
+}
+
+func lineAt(content string, offset int) int {
+	return strings.Count(content[:offset], "\n") + 1
+}
@@ -0,0 +1,149 @@
+package policy
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// MaxLines ограничивает число строк в пасте.
+type MaxLines int
+
+func (r MaxLines) Name() string { return "max_lines" }
+
+func (r MaxLines) Check(in Input) *Violation {
+	lines := strings.Count(in.Content, "\n") + 1
+	if strings.HasSuffix(in.Content, "\n") {
+		lines--
+	}
+	if lines <= int(r) {
+		return nil
+	}
+	return &Violation{
+		Rule:    r.Name(),
+		Code:    CodeMaxLines,
+		Message: fmt.Sprintf("в пасте %d строк, максимум %d", lines, int(r)),
+		Params:  map[string]any{"lines": lines, "max": int(r)},
+	}
+}
+
+// RegexDenylist отклоняет пасту, если в ней встречается любое из выражений.
+type RegexDenylist struct {
+	patterns []*regexp.Regexp
+}
+
+func NewRegexDenylist(patterns []string) (*RegexDenylist, error) {
+	rule := &RegexDenylist{}
+	for _, p := range patterns {
+		re, err := regexp.Compile(p)
+		if err != nil {
+			return nil, fmt.Errorf("некорректное выражение в denylist %q: %w", p, err)
+		}
+		rule.patterns = append(rule.patterns, re)
+	}
+	return rule, nil
+}
+
+func (r *RegexDenylist) Name() string { return "regex_denylist" }
+
+func (r *RegexDenylist) Check(in Input) *Violation {
+	for _, re := range r.patterns {
+		if loc := re.FindStringIndex(in.Content); loc != nil {
+			// само совпадение не возвращаем, только позицию и выражение
+			return &Violation{
+				Rule:    r.Name(),
+				Code:    CodeDenylist,
+				Message: "содержимое совпадает с запрещенным шаблоном",
+				Line:    lineAt(in.Content, loc[0]),
+				Value:   re.String(),
+			}
+		}
+	}
+	return nil
+}
+
+var urlHostRe = regexp.MustCompile(`(?i)\b[a-z][a-z0-9+.\-]*://(?:[^\s/@]+@)?([a-z0-9.\-]+)`)
+
+// DomainBlocklist отклоняет пасты со ссылками на заблокированные домены и их поддомены.
+type DomainBlocklist struct {
+	domains []string
+}
+
+func NewDomainBlocklist(domains []string) *DomainBlocklist {
+	normalized := normalizeList(domains)
+	for i, d := range normalized {
+		normalized[i] = strings.TrimPrefix(strings.TrimSuffix(d, "."), "*.")
+	}
+	return &DomainBlocklist{domains: normalized}
+}
+
+func (r *DomainBlocklist) Name() string { return "domain_blocklist" }
+
+func (r *DomainBlocklist) Check(in Input) *Violation {
+	for _, m := range urlHostRe.FindAllStringSubmatchIndex(in.Content, -1) {
+		host := strings.TrimSuffix(strings.ToLower(in.Content[m[2]:m[3]]), ".")
+		for _, domain := range r.domains {
+			if host == domain || strings.HasSuffix(host, "."+domain) {
+				return &Violation{
+					Rule:    r.Name(),
+					Code:    CodeBlockedDomain,
+					Message: fmt.Sprintf("ссылка на заблокированный домен %s", domain),
+					Line:    lineAt(in.Content, m[0]),
+					Value:   domain,
+					Params:  map[string]any{"value": domain},
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// RequiredTags требует, чтобы у пасты были все перечисленные теги.
+type RequiredTags []string
+
+func (r RequiredTags) Name() string { return "required_tags" }
+
+func (r RequiredTags) Check(in Input) *Violation {
+	have := tagSet(in.Tags)
+	for _, tag := range r {
+		if !have[tag] {
+			return &Violation{
+				Rule:    r.Name(),
+				Code:    CodeRequiredTag,
+				Message: fmt.Sprintf("отсутствует обязательный тег %s", tag),
+				Value:   tag,
+				Params:  map[string]any{"value": tag},
+			}
+		}
+	}
+	return nil
+}
+
+// ForbiddenTags запрещает перечисленные теги.
+type ForbiddenTags []string
+
+func (r ForbiddenTags) Name() string { return "forbidden_tags" }
+
+func (r ForbiddenTags) Check(in Input) *Violation {
+	have := tagSet(in.Tags)
+	for _, tag := range r {
+		if have[tag] {
+			return &Violation{
+				Rule:    r.Name(),
+				Code:    CodeForbiddenTag,
+				Message: fmt.Sprintf("тег %s запрещен", tag),
+				Value:   tag,
+				Params:  map[string]any{"value": tag},
+			}
+		}
+	}
+	return nil
+}
+
+func tagSet(tags []string) map[string]bool {
+	set := make(map[string]bool, len(tags))
+	for _, tag := range tags {
+		set[strings.ToLower(strings.TrimSpace(tag))] = true
+	}
+	return set
+}
@@ -0,0 +1,71 @@
+package ratelimit
+
+import (
+	"context"
+	"math"
+	"sync"
+	"time"
+)
+
+type bucket struct {
+	tokens float64
+	last   time.Time
+}
+
+// MemoryStore держит ведра в памяти процесса. Подходит для одной реплики:
+// у каждой реплики будет свой бюджет.
+type MemoryStore struct {
+	mu        sync.Mutex
+	buckets   map[string]*bucket
+	lastSweep time.Time
+	now       func() time.Time
+}
+
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{
+		buckets: make(map[string]*bucket),
+		now:     time.Now,
+	}
+}
+
+func (s *MemoryStore) Take(_ context.Context, key string, limit Limit) (Result, error) {
+	now := s.now()
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.sweep(now, limit.Period)
+
+	b, ok := s.buckets[key]
+	if !ok {
+		b = &bucket{tokens: float64(limit.Rate), last: now}
+		s.buckets[key] = b
+	}
+
+	elapsed := now.Sub(b.last).Seconds()
+	if elapsed > 0 {
+		b.tokens = math.Min(float64(limit.Rate), b.tokens+elapsed*limit.refillPerSecond())
+	}
+	b.last = now
+
+	allowed := b.tokens >= 1
+	if allowed {
+		b.tokens--
+	}
+	return limit.result(allowed, b.tokens), nil
+}
+
+// sweep раз в период выбрасывает ведра, которые успели наполниться:
+// они ничем не отличаются от отсутствующих.
+func (s *MemoryStore) sweep(now time.Time, period time.Duration) {
+	if now.Sub(s.lastSweep) < period {
+		return
+	}
+	s.lastSweep = now
+
+	for key, b := range s.buckets {
+		if now.Sub(b.last) >= period {
+			delete(s.buckets, key)
+		}
+	}
+}
@@ -0,0 +1,58 @@
+package ratelimit
+
+import (
+	"context"
+	"math"
+	"time"
+)
+
+const tracerName = "paste-service/internal/ratelimit"
+
+// Limit - token bucket: Rate запросов за Period, столько же можно потратить разом.
+type Limit struct {
+	Rate   int
+	Period time.Duration
+}
+
+func PerMinute(rate int) Limit {
+	return Limit{Rate: rate, Period: time.Minute}
+}
+
+func (l Limit) Enabled() bool {
+	return l.Rate > 0 && l.Period > 0
+}
+
+// refillPerSecond - сколько токенов возвращается в ведро за секунду.
+func (l Limit) refillPerSecond() float64 {
+	return float64(l.Rate) / l.Period.Seconds()
+}
+
+type Result struct {
+	Allowed   bool
+	Limit     int
+	Remaining int
+	// Reset - через сколько ведро снова будет полным
+	Reset time.Duration
+	// RetryAfter - через сколько появится следующий токен, если запрос отклонен
+	RetryAfter time.Duration
+}
+
+// result считает заголовки по числу токенов, оставшихся после запроса.
+func (l Limit) result(allowed bool, tokens float64) Result {
+	refill := l.refillPerSecond()
+	res := Result{
+		Allowed:   allowed,
+		Limit:     l.Rate,
+		Remaining: int(math.Floor(tokens)),
+		Reset:     time.Duration((float64(l.Rate) - tokens) / refill * float64(time.Second)),
+	}
+	if !allowed {
+		res.RetryAfter = time.Duration((1 - tokens) / refill * float64(time.Second))
+	}
+	return res
+}
+
+// Store хранит состояние ведер. Ключ уже содержит класс запроса и клиента.
+type Store interface {
+	Take(ctx context.Context, key string, limit Limit) (Result, error)
+}
@@ -0,0 +1,84 @@
+package ratelimit
+
+import (
+	"context"
+	"strconv"
+
+	"paste-service/internal/telemetry"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// takeScript атомарно пополняет ведро и забирает токен. Время берется из Redis,
+// чтобы расхождение часов реплик не влияло на бюджет.
+var takeScript = redis.NewScript(`
+local rate = tonumber(ARGV[1])
+local period_ms = tonumber(ARGV[2])
+local t = redis.call('TIME')
+local now_ms = tonumber(t[1]) * 1000 + math.floor(tonumber(t[2]) / 1000)
+
+local data = redis.call('HMGET', KEYS[1], 'tokens', 'ts')
+local tokens = tonumber(data[1])
+local ts = tonumber(data[2])
+if tokens == nil then
+	tokens = rate
+	ts = now_ms
+end
+
+local elapsed = math.max(0, now_ms - ts)
+tokens = math.min(rate, tokens + elapsed * rate / period_ms)
+
+local allowed = 0
+if tokens >= 1 then
+	tokens = tokens - 1
+	allowed = 1
+end
+
+redis.call('HSET', KEYS[1], 'tokens', tostring(tokens), 'ts', now_ms)
+redis.call('PEXPIRE', KEYS[1], period_ms)
+return {allowed, tostring(tokens)}
+`)
+
+// RedisStore делит бюджет между всеми репликами.
+type RedisStore struct {
+	client *redis.Client
+	prefix string
+}
+
+func NewRedisStore(redisURL string) (*RedisStore, error) {
+	options, err := redis.ParseURL(redisURL)
+	if err != nil {
+		return nil, err
+	}
+
+	client := redis.NewClient(options)
+	if err := client.Ping(context.Background()).Err(); err != nil {
+		client.Close()
+		return nil, err
+	}
+
+	return &RedisStore{client: client, prefix: "ratelimit:"}, nil
+}
+
+func (s *RedisStore) Take(ctx context.Context, key string, limit Limit) (_ Result, err error) {
+	ctx, span := telemetry.Start(ctx, tracerName, "RedisStore.Take")
+	defer func() { telemetry.End(span, err) }()
+
+	values, err := takeScript.Run(ctx, s.client, []string{s.prefix + key}, limit.Rate, limit.Period.Milliseconds()).Slice()
+	if err != nil {
+		return Result{}, err
+	}
+
+	allowed, _ := values[0].(int64)
+	tokensStr, _ := values[1].(string)
+	tokens, err := strconv.ParseFloat(tokensStr, 64)
+	if err != nil {
+		return Result{}, err
+	}
+
+	return limit.result(allowed == 1, tokens), nil
+}
+
+func (s *RedisStore) Close() error {
+	return s.client.Close()
+}
@@ -0,0 +1,153 @@
+package secrets
+
+import (
+	"math"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+const (
+	ModeReject  = "reject"  // отклонить пасту целиком
+	ModeRedact  = "redact"  // заменить найденное на [REDACTED:тип]
+	ModePrivate = "private" // сохранить как есть, но сделать пасту приватной
+)
+
+// Finding - найденный секрет. Само значение наружу не отдается, только тип и позиция.
+type Finding struct {
+	Type   string `json:"type"`
+	Line   int    `json:"line"`
+	Column int    `json:"column"`
+
+	start, end int
+}
+
+type rule struct {
+	name string
+	re   *regexp.Regexp
+	// номер группы с самим секретом, 0 - все совпадение
+	group int
+	// проверять энтропию значения, чтобы не ловить password=changeme из примеров
+	checkEntropy bool
+}
+
+var defaultRules = []rule{
+	{name: "private_key", re: regexp.MustCompile(`(?s)-----BEGIN [A-Z ]*PRIVATE KEY(?: BLOCK)?-----.*?(?:-----END [A-Z ]*PRIVATE KEY(?: BLOCK)?-----|\z)`)},
+	{name: "aws_access_key_id", re: regexp.MustCompile(`\b(?:AKIA|ASIA|AGPA|AIDA|AROA|ANPA|ANVA|AIPA)[0-9A-Z]{16}\b`)},
+	{name: "aws_secret_access_key", re: regexp.MustCompile(`(?i)aws_?(?:secret_?access_?key|secret_?key)\s*[:=]\s*["']?([A-Za-z0-9/+=]{40})\b`), group: 1},
+	{name: "github_token", re: regexp.MustCompile(`\b(?:gh[pousr]_[A-Za-z0-9]{36}|github_pat_[A-Za-z0-9_]{82})\b`)},
+	{name: "gitlab_token", re: regexp.MustCompile(`\bglpat-[A-Za-z0-9_\-]{20}\b`)},
+	{name: "slack_token", re: regexp.MustCompile(`\bxox[abposr]-[A-Za-z0-9-]{10,}\b`)},
+	{name: "google_api_key", re: regexp.MustCompile(`\bAIza[0-9A-Za-z_\-]{35}\b`)},
+	{name: "stripe_key", re: regexp.MustCompile(`\b(?:sk|rk)_live_[0-9A-Za-z]{24,}\b`)},
+	{name: "paste_service_key", re: regexp.MustCompile(`\bpsk_[0-9a-f]{64}\b`)},
+	{name: "jwt", re: regexp.MustCompile(`\beyJ[A-Za-z0-9_-]{10,}\.eyJ[A-Za-z0-9_-]{10,}\.[A-Za-z0-9_-]{10,}`)},
+	{name: "bearer_token", re: regexp.MustCompile(`(?i)\bbearer\s+([A-Za-z0-9\-._~+/]{20,}=*)`), group: 1, checkEntropy: true},
+	{name: "basic_auth_url", re: regexp.MustCompile(`\b[a-z][a-z0-9+.\-]*://[^\s:/@]+:([^\s:/@]{3,})@`), group: 1},
+	{
+		name:         "generic_secret",
+		re:           regexp.MustCompile(`(?i)\b(?:password|passwd|pwd|secret|token|api[_-]?key|access[_-]?key|client[_-]?secret|auth[_-]?token)["']?\s*[:=]\s*["']?([^\s"',;]{8,})`),
+		group:        1,
+		checkEntropy: true,
+	},
+}
+
+type Scanner struct {
+	rules            []rule
+	entropyThreshold float64
+}
+
+// NewScanner создает сканер со встроенным набором правил. entropyThreshold -
+// минимальная энтропия Шеннона (бит на символ) для правил с проверкой энтропии.
+func NewScanner(entropyThreshold float64) *Scanner {
+	return &Scanner{
+		rules:            defaultRules,
+		entropyThreshold: entropyThreshold,
+	}
+}
+
+// Scan возвращает найденные секреты, отсортированные по позиции. Пересекающиеся
+// совпадения схлопываются в первое (например, JWT внутри Bearer).
+func (s *Scanner) Scan(content string) []Finding {
+	var findings []Finding
+	for _, r := range s.rules {
+		for _, loc := range r.re.FindAllStringSubmatchIndex(content, -1) {
+			start, end := loc[0], loc[1]
+			if r.group > 0 {
+				if len(loc) <= 2*r.group+1 || loc[2*r.group] < 0 {
+					continue
+				}
+				start, end = loc[2*r.group], loc[2*r.group+1]
+			}
+
+			if r.checkEntropy && ShannonEntropy(content[start:end]) < s.entropyThreshold {
+				continue
+			}
+
+			findings = append(findings, Finding{Type: r.name, start: start, end: end})
+		}
+	}
+
+	sort.SliceStable(findings, func(i, j int) bool {
+		return findings[i].start < findings[j].start
+	})
+
+	result := findings[:0]
+	lastEnd := -1
+	for _, f := range findings {
+		if f.start < lastEnd {
+			continue
+		}
+		f.Line, f.Column = position(content, f.start)
+		result = append(result, f)
+		lastEnd = f.end
+	}
+
+	return result
+}
+
+// Redact заменяет найденные секреты на плейсхолдеры. findings должны быть
+// результатом Scan для того же content.
+func Redact(content string, findings []Finding) string {
+	if len(findings) == 0 {
+		return content
+	}
+
+	var b strings.Builder
+	b.Grow(len(content))
+	prev := 0
+	for _, f := range findings {
+		b.WriteString(content[prev:f.start])
+		b.WriteString("[REDACTED:" + f.Type + "]")
+		prev = f.end
+	}
+	b.WriteString(content[prev:])
+	return b.String()
+}
+
+func ShannonEntropy(s string) float64 {
+	if s == "" {
+		return 0
+	}
+
+	freq := make(map[rune]int)
+	total := 0
+	for _, r := range s {
+		freq[r]++
+		total++
+	}
+
+	var entropy float64
+	for _, count := range freq {
+		p := float64(count) / float64(total)
+		entropy -= p * math.Log2(p)
+	}
+	return entropy
+}
+
+func position(content string, offset int) (line, column int) {
+	line = strings.Count(content[:offset], "\n") + 1
+	lineStart := strings.LastIndexByte(content[:offset], '\n') + 1
+	column = len([]rune(content[lineStart:offset])) + 1
+	return line, column
+}
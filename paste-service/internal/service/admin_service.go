@@ -0,0 +1,461 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"paste-service/internal/model"
+	"paste-service/internal/telemetry"
+	"paste-service/repository"
+
+	"go.opentelemetry.io/otel/attribute"
+)
+
+const (
+	AuditActionListPastes = "paste.list"
+	AuditActionGetPaste   = "paste.get"
+	AuditActionDelete     = "paste.delete"
+	AuditActionSetExpiry  = "paste.set_expiry"
+	AuditActionLock       = "paste.lock"
+	AuditActionUnlock     = "paste.unlock"
+	AuditActionPurgeCache = "cache.purge"
+	AuditActionStats      = "stats.view"
+	AuditActionListAudit  = "audit.list"
+	AuditActionListReport = "report.list"
+	AuditActionResolve    = "report.resolve"
+	AuditActionKeyCreate  = "apikey.create"
+	AuditActionKeyList    = "apikey.list"
+	AuditActionKeyRevoke  = "apikey.revoke"
+)
+
+// Решения модератора по жалобе. Применяются ко всем открытым жалобам на ту же пасту.
+const (
+	ReportActionDismiss = "dismiss" // жалобы необоснованны, паста возвращается в листинги
+	ReportActionHide    = "hide"    // паста остается скрытой
+	ReportActionLock    = "lock"    // скрыть и запретить редактирование
+	ReportActionDelete  = "delete"  // удалить пасту
+)
+
+var errUnknownReportAction = errors.New("неизвестное решение по жалобе")
+
+// Actor - кто выполняет действие в админке. Попадает в журнал аудита.
+type Actor struct {
+	Name      string
+	RemoteIP  string
+	RequestID string
+}
+
+type AdminPasteResponse struct {
+	ID          string     `json:"id"`
+	Slug        string     `json:"slug"`
+	Content     string     `json:"content,omitempty"`
+	ContentSize int        `json:"content_size"`
+	Tags        []string   `json:"tags"`
+	ViewCount   int        `json:"view_count"`
+	CreatedAt   time.Time  `json:"created_at"`
+	UpdatedAt   time.Time  `json:"updated_at"`
+	LastViewed  *time.Time `json:"last_viewed,omitempty"`
+	Expires     *time.Time `json:"expires,omitempty"`
+	Expired     bool       `json:"expired"`
+	Locked      bool       `json:"locked"`
+	Hidden      bool       `json:"hidden"`
+	Visibility  string     `json:"visibility"`
+	OwnerID     *string    `json:"owner_id,omitempty"`
+	APIKeyID    *string    `json:"api_key_id,omitempty"`
+	Creator     string     `json:"creator,omitempty"`
+}
+
+type AdminPasteList struct {
+	Items []AdminPasteResponse `json:"items"`
+	Total int64                `json:"total"`
+}
+
+type AuditEntryResponse struct {
+	ID        string          `json:"id"`
+	Actor     string          `json:"actor"`
+	Action    string          `json:"action"`
+	Target    string          `json:"target,omitempty"`
+	Details   json.RawMessage `json:"details,omitempty"`
+	RemoteIP  string          `json:"remote_ip,omitempty"`
+	RequestID string          `json:"request_id,omitempty"`
+	CreatedAt time.Time       `json:"created_at"`
+}
+
+type ResolveReportResponse struct {
+	Report   ReportResponse `json:"report"`
+	Action   string         `json:"action"`
+	Resolved int64          `json:"resolved"`
+}
+
+type AdminService struct {
+	repo    *repository.PasteRepository
+	audit   *repository.AuditRepository
+	reports *repository.ReportRepository
+	keys    *APIKeyService
+}
+
+func NewAdminService(
+	repo *repository.PasteRepository,
+	audit *repository.AuditRepository,
+	reports *repository.ReportRepository,
+	keys *APIKeyService,
+) *AdminService {
+	return &AdminService{
+		repo:    repo,
+		audit:   audit,
+		reports: reports,
+		keys:    keys,
+	}
+}
+
+func convertPasteToAdminResponse(paste *model.Paste, withContent bool) AdminPasteResponse {
+	resp := AdminPasteResponse{
+		ID:          paste.ID,
+		Slug:        paste.Slug,
+		ContentSize: len(paste.Content),
+		Tags:        paste.Tags,
+		ViewCount:   paste.ViewCount,
+		CreatedAt:   paste.CreatedAt,
+		UpdatedAt:   paste.UpdatedAt,
+		LastViewed:  paste.LastViewed,
+		Expires:     paste.Expires,
+		Expired:     paste.HasExpired(),
+		Locked:      paste.Locked,
+		Hidden:      paste.Hidden,
+		Visibility:  paste.Visibility,
+		OwnerID:     paste.OwnerID,
+		APIKeyID:    paste.APIKeyID,
+		Creator:     paste.Creator,
+	}
+	if withContent {
+		resp.Content = paste.Content
+	}
+	return resp
+}
+
+func (s *AdminService) ListPastes(ctx context.Context, actor Actor, filter repository.PasteFilter) (_ *AdminPasteList, err error) {
+	ctx, span := telemetry.Start(ctx, tracerName, "AdminService.ListPastes")
+	defer func() { telemetry.End(span, err) }()
+
+	if filter.Limit <= 0 || filter.Limit > 500 {
+		filter.Limit = 50
+	}
+	if filter.Offset < 0 {
+		filter.Offset = 0
+	}
+
+	pastes, total, err := s.repo.ListPastes(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+
+	s.record(ctx, actor, AuditActionListPastes, "", map[string]interface{}{
+		"limit":  filter.Limit,
+		"offset": filter.Offset,
+		"total":  total,
+	})
+
+	result := &AdminPasteList{
+		Items: make([]AdminPasteResponse, len(pastes)),
+		Total: total,
+	}
+	for i := range pastes {
+		result.Items[i] = convertPasteToAdminResponse(&pastes[i], false)
+	}
+	return result, nil
+}
+
+func (s *AdminService) GetPaste(ctx context.Context, actor Actor, slug string) (_ *AdminPasteResponse, err error) {
+	ctx, span := telemetry.Start(ctx, tracerName, "AdminService.GetPaste",
+		attribute.String("paste.slug", slug),
+	)
+	defer func() { telemetry.End(span, err) }()
+
+	paste, err := s.repo.GetPasteBySlugAny(ctx, slug)
+	if err != nil {
+		return nil, mapRepositoryError(err)
+	}
+
+	s.record(ctx, actor, AuditActionGetPaste, slug, nil)
+
+	resp := convertPasteToAdminResponse(paste, true)
+	return &resp, nil
+}
+
+func (s *AdminService) DeletePaste(ctx context.Context, actor Actor, slug string) (err error) {
+	ctx, span := telemetry.Start(ctx, tracerName, "AdminService.DeletePaste",
+		attribute.String("paste.slug", slug),
+	)
+	defer func() { telemetry.End(span, err) }()
+
+	if err := s.repo.DeletePaste(ctx, slug); err != nil {
+		return mapRepositoryError(err)
+	}
+
+	s.record(ctx, actor, AuditActionDelete, slug, nil)
+	return nil
+}
+
+func (s *AdminService) SetExpiry(ctx context.Context, actor Actor, slug string, expires *time.Time) (_ *AdminPasteResponse, err error) {
+	ctx, span := telemetry.Start(ctx, tracerName, "AdminService.SetExpiry",
+		attribute.String("paste.slug", slug),
+	)
+	defer func() { telemetry.End(span, err) }()
+
+	if err := s.repo.SetExpires(ctx, slug, expires); err != nil {
+		return nil, mapRepositoryError(err)
+	}
+
+	s.record(ctx, actor, AuditActionSetExpiry, slug, map[string]interface{}{
+		"expires": expires,
+	})
+
+	return s.reload(ctx, slug)
+}
+
+func (s *AdminService) SetLocked(ctx context.Context, actor Actor, slug string, locked bool) (_ *AdminPasteResponse, err error) {
+	ctx, span := telemetry.Start(ctx, tracerName, "AdminService.SetLocked",
+		attribute.String("paste.slug", slug),
+		attribute.Bool("paste.locked", locked),
+	)
+	defer func() { telemetry.End(span, err) }()
+
+	if err := s.repo.SetLocked(ctx, slug, locked); err != nil {
+		return nil, mapRepositoryError(err)
+	}
+
+	action := AuditActionLock
+	if !locked {
+		action = AuditActionUnlock
+	}
+	s.record(ctx, actor, action, slug, nil)
+
+	return s.reload(ctx, slug)
+}
+
+// PurgeCache удаляет отдельные ключи или весь кэш целиком.
+func (s *AdminService) PurgeCache(ctx context.Context, actor Actor, keys []string, all bool) (err error) {
+	ctx, span := telemetry.Start(ctx, tracerName, "AdminService.PurgeCache",
+		attribute.Int("cache.keys", len(keys)),
+		attribute.Bool("cache.all", all),
+	)
+	defer func() { telemetry.End(span, err) }()
+
+	if all {
+		s.repo.Cache.Clear(ctx)
+	} else {
+		for _, key := range keys {
+			s.repo.Cache.Invalidate(ctx, key)
+		}
+	}
+
+	s.record(ctx, actor, AuditActionPurgeCache, "", map[string]interface{}{
+		"keys": keys,
+		"all":  all,
+	})
+	return nil
+}
+
+func (s *AdminService) GetStats(ctx context.Context, actor Actor) (_ *repository.PasteStats, err error) {
+	ctx, span := telemetry.Start(ctx, tracerName, "AdminService.GetStats")
+	defer func() { telemetry.End(span, err) }()
+
+	stats, err := s.repo.GetStats(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	s.record(ctx, actor, AuditActionStats, "", nil)
+	return stats, nil
+}
+
+func (s *AdminService) ListAudit(ctx context.Context, actor Actor, byActor, action string, limit, offset int) (_ []AuditEntryResponse, err error) {
+	ctx, span := telemetry.Start(ctx, tracerName, "AdminService.ListAudit")
+	defer func() { telemetry.End(span, err) }()
+
+	if limit <= 0 || limit > 500 {
+		limit = 50
+	}
+	if offset < 0 {
+		offset = 0
+	}
+
+	entries, err := s.audit.List(ctx, byActor, action, limit, offset)
+	if err != nil {
+		return nil, err
+	}
+
+	s.record(ctx, actor, AuditActionListAudit, "", nil)
+
+	result := make([]AuditEntryResponse, len(entries))
+	for i, entry := range entries {
+		result[i] = AuditEntryResponse{
+			ID:        entry.ID,
+			Actor:     entry.Actor,
+			Action:    entry.Action,
+			Target:    entry.Target,
+			Details:   json.RawMessage(entry.Details),
+			RemoteIP:  entry.RemoteIP,
+			RequestID: entry.RequestID,
+			CreatedAt: entry.CreatedAt,
+		}
+	}
+	return result, nil
+}
+
+func (s *AdminService) ListReports(ctx context.Context, actor Actor, status, slug string, limit, offset int) (_ []ReportResponse, err error) {
+	ctx, span := telemetry.Start(ctx, tracerName, "AdminService.ListReports")
+	defer func() { telemetry.End(span, err) }()
+
+	if limit <= 0 || limit > 500 {
+		limit = 50
+	}
+	if offset < 0 {
+		offset = 0
+	}
+
+	reports, err := s.reports.List(ctx, status, slug, limit, offset)
+	if err != nil {
+		return nil, err
+	}
+
+	s.record(ctx, actor, AuditActionListReport, slug, map[string]interface{}{
+		"status": status,
+	})
+
+	result := make([]ReportResponse, len(reports))
+	for i := range reports {
+		result[i] = convertReportToResponse(&reports[i])
+	}
+	return result, nil
+}
+
+// ResolveReport применяет решение к пасте и закрывает все открытые жалобы на нее.
+func (s *AdminService) ResolveReport(ctx context.Context, actor Actor, id, action string) (_ *ResolveReportResponse, err error) {
+	ctx, span := telemetry.Start(ctx, tracerName, "AdminService.ResolveReport",
+		attribute.String("report.action", action),
+	)
+	defer func() { telemetry.End(span, err) }()
+
+	report, err := s.reports.GetByID(ctx, id)
+	if err != nil {
+		if errors.Is(err, repository.ErrReportNotFound) {
+			return nil, ErrReportNotFound
+		}
+		return nil, err
+	}
+
+	status := model.ReportStatusResolved
+	slug := report.PasteSlug
+	switch action {
+	case ReportActionDismiss:
+		status = model.ReportStatusDismissed
+		err = s.repo.SetHidden(ctx, slug, false)
+	case ReportActionHide:
+		err = s.repo.SetHidden(ctx, slug, true)
+	case ReportActionLock:
+		if err = s.repo.SetHidden(ctx, slug, true); err == nil {
+			err = s.repo.SetLocked(ctx, slug, true)
+		}
+	case ReportActionDelete:
+		err = s.repo.DeletePaste(ctx, slug)
+	default:
+		return nil, fmt.Errorf("%w: %w", ErrInvalidReport, model.Invalid("action", "action.invalid", errUnknownReportAction,
+			map[string]any{"value": action}))
+	}
+	// паста могла быть удалена раньше, жалобы при этом все равно нужно закрыть
+	if err != nil && !errors.Is(err, repository.ErrPasteNotFound) {
+		return nil, err
+	}
+
+	resolved, err := s.reports.ResolveOpenForPaste(ctx, slug, status, action, actor.Name)
+	if err != nil {
+		return nil, err
+	}
+
+	s.record(ctx, actor, AuditActionResolve, slug, map[string]interface{}{
+		"report_id": id,
+		"action":    action,
+		"resolved":  resolved,
+	})
+
+	report, err = s.reports.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	return &ResolveReportResponse{
+		Report:   convertReportToResponse(report),
+		Action:   action,
+		Resolved: resolved,
+	}, nil
+}
+
+// CreateAPIKey выдает ключ без владельца, в том числе с областью admin.
+func (s *AdminService) CreateAPIKey(ctx context.Context, actor Actor, req CreateAPIKeyRequest) (_ *APIKeyResponse, err error) {
+	ctx, span := telemetry.Start(ctx, tracerName, "AdminService.CreateAPIKey")
+	defer func() { telemetry.End(span, err) }()
+
+	key, err := s.keys.issue(ctx, "", actor.Name, req, true)
+	if err != nil {
+		return nil, err
+	}
+
+	s.record(ctx, actor, AuditActionKeyCreate, key.ID, map[string]interface{}{
+		"name":   key.Name,
+		"prefix": key.Prefix,
+		"scopes": key.Scopes,
+	})
+	return key, nil
+}
+
+func (s *AdminService) ListAPIKeys(ctx context.Context, actor Actor, userID string, limit, offset int) (_ []APIKeyResponse, err error) {
+	ctx, span := telemetry.Start(ctx, tracerName, "AdminService.ListAPIKeys")
+	defer func() { telemetry.End(span, err) }()
+
+	keys, err := s.keys.list(ctx, userID, limit, offset)
+	if err != nil {
+		return nil, err
+	}
+
+	s.record(ctx, actor, AuditActionKeyList, userID, nil)
+	return keys, nil
+}
+
+// RevokeAPIKey отзывает любой ключ, включая пользовательские.
+func (s *AdminService) RevokeAPIKey(ctx context.Context, actor Actor, id string) (err error) {
+	ctx, span := telemetry.Start(ctx, tracerName, "AdminService.RevokeAPIKey")
+	defer func() { telemetry.End(span, err) }()
+
+	if err := s.keys.revoke(ctx, id, ""); err != nil {
+		return err
+	}
+
+	s.record(ctx, actor, AuditActionKeyRevoke, id, nil)
+	return nil
+}
+
+func (s *AdminService) reload(ctx context.Context, slug string) (*AdminPasteResponse, error) {
+	paste, err := s.repo.GetPasteBySlugAny(ctx, slug)
+	if err != nil {
+		return nil, mapRepositoryError(err)
+	}
+	resp := convertPasteToAdminResponse(paste, false)
+	return &resp, nil
+}
+
+// record пишет действие в журнал аудита и в лог. Ошибка записи в журнал
+// не откатывает уже выполненное действие, но логируется как ошибка.
+func (s *AdminService) record(ctx context.Context, actor Actor, action, target string, details map[string]interface{}) {
+	slog.InfoContext(ctx, "admin action",
+		slog.String("actor", actor.Name),
+		slog.String("action", action),
+		slog.String("target", target),
+		slog.String("remote_ip", actor.RemoteIP),
+	)
+	writeAudit(ctx, s.audit, actor, action, target, details)
+}
@@ -0,0 +1,242 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strings"
+	"time"
+
+	"paste-service/internal/expiry"
+	"paste-service/internal/model"
+	"paste-service/internal/telemetry"
+	"paste-service/repository"
+
+	"github.com/google/uuid"
+)
+
+// apiKeyPrefix помогает сканерам секретов узнавать наши ключи в чужих репозиториях.
+const apiKeyPrefix = "psk_"
+
+// lastUsedInterval - как часто обновлять last_used_at у активно используемого ключа.
+const lastUsedInterval = time.Minute
+
+var (
+	ErrInvalidAPIKey        = errors.New("недействительный API-ключ")
+	ErrInvalidAPIKeyRequest = errors.New("некорректные параметры ключа")
+	ErrAPIKeyNotFound       = errors.New("ключ не найден")
+	ErrInsufficientScope    = errors.New("у ключа нет нужной области действия")
+
+	errAdminScopeForbidden = errors.New("область admin выдается только администратором")
+	errExpiryNotPositive   = errors.New("срок действия должен быть положительным")
+)
+
+type CreateAPIKeyRequest struct {
+	Name      string           `json:"name"`
+	Scopes    []string         `json:"scopes"`
+	ExpiresIn *expiry.Duration `json:"expires_in,omitempty"`
+}
+
+type APIKeyResponse struct {
+	ID         string     `json:"id"`
+	Name       string     `json:"name"`
+	Prefix     string     `json:"prefix"`
+	Key        string     `json:"key,omitempty"` // только в ответе на создание
+	Scopes     []string   `json:"scopes"`
+	UserID     *string    `json:"user_id,omitempty"`
+	ExpiresAt  *time.Time `json:"expires_at,omitempty"`
+	LastUsedAt *time.Time `json:"last_used_at,omitempty"`
+	Revoked    bool       `json:"revoked"`
+	RevokedAt  *time.Time `json:"revoked_at,omitempty"`
+	CreatedBy  string     `json:"created_by,omitempty"`
+	CreatedAt  time.Time  `json:"created_at"`
+}
+
+// APIKeyPrincipal - ключ, которым подписан запрос.
+type APIKeyPrincipal struct {
+	ID     string
+	Name   string
+	Prefix string
+	UserID string // пусто у ключей, выданных администратором
+	Scopes []string
+}
+
+func (p *APIKeyPrincipal) HasScope(scope string) bool {
+	for _, s := range p.Scopes {
+		if s == scope {
+			return true
+		}
+	}
+	return false
+}
+
+type APIKeyService struct {
+	keys *repository.APIKeyRepository
+}
+
+func NewAPIKeyService(keys *repository.APIKeyRepository) *APIKeyService {
+	return &APIKeyService{keys: keys}
+}
+
+func convertAPIKeyToResponse(key *model.APIKey) APIKeyResponse {
+	return APIKeyResponse{
+		ID:         key.ID,
+		Name:       key.Name,
+		Prefix:     key.Prefix,
+		Scopes:     key.Scopes,
+		UserID:     key.UserID,
+		ExpiresAt:  key.ExpiresAt,
+		LastUsedAt: key.LastUsedAt,
+		Revoked:    key.Revoked,
+		RevokedAt:  key.RevokedAt,
+		CreatedBy:  key.CreatedBy,
+		CreatedAt:  key.CreatedAt,
+	}
+}
+
+// issue создает ключ. Область admin может выдать только администратор.
+func (s *APIKeyService) issue(ctx context.Context, userID, createdBy string, req CreateAPIKeyRequest, allowAdmin bool) (*APIKeyResponse, error) {
+	name := strings.TrimSpace(req.Name)
+	for _, scope := range req.Scopes {
+		if scope == model.ScopeAdmin && !allowAdmin {
+			return nil, fmt.Errorf("%w: %w", ErrInvalidAPIKeyRequest, model.Invalid("scopes", "scopes.admin_forbidden", errAdminScopeForbidden, nil))
+		}
+	}
+	expiresIn := req.ExpiresIn.Ptr()
+	if expiresIn != nil && *expiresIn <= 0 {
+		return nil, fmt.Errorf("%w: %w", ErrInvalidAPIKeyRequest, model.Invalid("expires_in", "expires_in.not_positive", errExpiryNotPositive, nil))
+	}
+
+	secret, err := generateToken()
+	if err != nil {
+		return nil, fmt.Errorf("ошибка генерации токена: %v", err)
+	}
+	raw := apiKeyPrefix + secret
+
+	id, err := uuid.NewV7()
+	if err != nil {
+		return nil, fmt.Errorf("ошибка генерации UUID v7: %v", err)
+	}
+
+	key := &model.APIKey{
+		ID:        id.String(),
+		Name:      name,
+		Prefix:    raw[:len(apiKeyPrefix)+8],
+		KeyHash:   lookupHash(raw),
+		Scopes:    req.Scopes,
+		CreatedBy: createdBy,
+	}
+	if userID != "" {
+		key.UserID = &userID
+	}
+	if expiresIn != nil {
+		expiresAt := time.Now().Add(*expiresIn)
+		key.ExpiresAt = &expiresAt
+	}
+
+	if err := s.keys.Create(ctx, key); err != nil {
+		var validationErr *model.ValidationError
+		if errors.As(err, &validationErr) {
+			return nil, fmt.Errorf("%w: %w", ErrInvalidAPIKeyRequest, err)
+		}
+		return nil, err
+	}
+
+	slog.InfoContext(ctx, "api key created",
+		slog.String("key_id", key.ID),
+		slog.String("key_prefix", key.Prefix),
+		slog.Any("scopes", key.Scopes),
+		slog.String("created_by", createdBy),
+	)
+
+	resp := convertAPIKeyToResponse(key)
+	resp.Key = raw
+	return &resp, nil
+}
+
+func (s *APIKeyService) CreateForUser(ctx context.Context, user *UserResponse, req CreateAPIKeyRequest) (_ *APIKeyResponse, err error) {
+	ctx, span := telemetry.Start(ctx, tracerName, "APIKeyService.CreateForUser")
+	defer func() { telemetry.End(span, err) }()
+
+	return s.issue(ctx, user.ID, user.Username, req, false)
+}
+
+func (s *APIKeyService) ListForUser(ctx context.Context, userID string, limit, offset int) (_ []APIKeyResponse, err error) {
+	ctx, span := telemetry.Start(ctx, tracerName, "APIKeyService.ListForUser")
+	defer func() { telemetry.End(span, err) }()
+
+	return s.list(ctx, userID, limit, offset)
+}
+
+func (s *APIKeyService) RevokeForUser(ctx context.Context, userID, id string) (err error) {
+	ctx, span := telemetry.Start(ctx, tracerName, "APIKeyService.RevokeForUser")
+	defer func() { telemetry.End(span, err) }()
+
+	return s.revoke(ctx, id, userID)
+}
+
+func (s *APIKeyService) list(ctx context.Context, userID string, limit, offset int) ([]APIKeyResponse, error) {
+	if limit <= 0 || limit > 200 {
+		limit = 50
+	}
+
+	keys, err := s.keys.List(ctx, userID, limit, offset)
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]APIKeyResponse, len(keys))
+	for i := range keys {
+		result[i] = convertAPIKeyToResponse(&keys[i])
+	}
+	return result, nil
+}
+
+func (s *APIKeyService) revoke(ctx context.Context, id, userID string) error {
+	if err := s.keys.Revoke(ctx, id, userID); err != nil {
+		if errors.Is(err, repository.ErrAPIKeyNotFound) {
+			return ErrAPIKeyNotFound
+		}
+		return err
+	}
+
+	slog.InfoContext(ctx, "api key revoked", slog.String("key_id", id))
+	return nil
+}
+
+// Authenticate проверяет ключ из заголовка Authorization и отмечает его использование.
+func (s *APIKeyService) Authenticate(ctx context.Context, raw string) (_ *APIKeyPrincipal, err error) {
+	ctx, span := telemetry.Start(ctx, tracerName, "APIKeyService.Authenticate")
+	defer func() { telemetry.End(span, err) }()
+
+	if !strings.HasPrefix(raw, apiKeyPrefix) {
+		return nil, ErrInvalidAPIKey
+	}
+
+	key, err := s.keys.GetByHash(ctx, lookupHash(raw))
+	if err != nil {
+		if errors.Is(err, repository.ErrAPIKeyNotFound) {
+			return nil, ErrInvalidAPIKey
+		}
+		return nil, err
+	}
+	if !key.IsActive() {
+		return nil, ErrInvalidAPIKey
+	}
+
+	if err := s.keys.TouchLastUsed(ctx, key.ID, lastUsedInterval); err != nil {
+		slog.WarnContext(ctx, "api key last used update failed", slog.String("key_id", key.ID), slog.Any("error", err))
+	}
+
+	principal := &APIKeyPrincipal{
+		ID:     key.ID,
+		Name:   key.Name,
+		Prefix: key.Prefix,
+		Scopes: key.Scopes,
+	}
+	if key.UserID != nil {
+		principal.UserID = *key.UserID
+	}
+	return principal, nil
+}
@@ -0,0 +1,51 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"log/slog"
+
+	"paste-service/internal/model"
+	"paste-service/repository"
+
+	"github.com/google/uuid"
+)
+
+// AuditActionPatch - правка пасты ее владельцем или держателем токена через PATCH.
+const AuditActionPatch = "paste.patch"
+
+// writeAudit сохраняет запись журнала аудита. Ошибка записи не откатывает
+// уже выполненное действие, но логируется как ошибка.
+func writeAudit(ctx context.Context, audit *repository.AuditRepository, actor Actor, action, target string, details map[string]interface{}) {
+	if details == nil {
+		details = map[string]interface{}{}
+	}
+	detailsJSON, err := json.Marshal(details)
+	if err != nil {
+		slog.ErrorContext(ctx, "audit details marshal failed", slog.String("action", action), slog.Any("error", err))
+		detailsJSON = []byte("{}")
+	}
+
+	id, err := uuid.NewV7()
+	if err != nil {
+		slog.ErrorContext(ctx, "audit id generation failed", slog.Any("error", err))
+		return
+	}
+
+	entry := &model.AuditEntry{
+		ID:        id.String(),
+		Actor:     actor.Name,
+		Action:    action,
+		Target:    target,
+		Details:   string(detailsJSON),
+		RemoteIP:  actor.RemoteIP,
+		RequestID: actor.RequestID,
+	}
+	if err := audit.Create(ctx, entry); err != nil {
+		slog.ErrorContext(ctx, "audit entry write failed",
+			slog.String("action", action),
+			slog.String("target", target),
+			slog.Any("error", err),
+		)
+	}
+}
@@ -0,0 +1,198 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strings"
+	"time"
+
+	"paste-service/config"
+	"paste-service/internal/model"
+	"paste-service/internal/telemetry"
+	"paste-service/repository"
+
+	"github.com/google/uuid"
+	"golang.org/x/crypto/bcrypt"
+)
+
+var (
+	ErrInvalidAccount       = errors.New("некорректные данные учетной записи")
+	ErrUsernameTaken        = errors.New("имя пользователя занято")
+	ErrInvalidCredentials   = errors.New("неверное имя пользователя или пароль")
+	ErrRegistrationDisabled = errors.New("регистрация закрыта")
+	ErrUnauthenticated      = errors.New("требуется вход")
+)
+
+type UserResponse struct {
+	ID        string    `json:"id"`
+	Username  string    `json:"username"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// SessionResponse - выданная сессия. Токен уходит клиенту только в cookie.
+type SessionResponse struct {
+	User      UserResponse `json:"user"`
+	Token     string       `json:"-"`
+	ExpiresAt time.Time    `json:"expires_at"`
+}
+
+type AuthService struct {
+	users *repository.UserRepository
+	cfg   config.AuthConfig
+	// хэш для сравнения, когда пользователя нет: вход по несуществующему
+	// имени занимает столько же времени, сколько по существующему
+	dummyHash []byte
+}
+
+func NewAuthService(users *repository.UserRepository, cfg config.AuthConfig) *AuthService {
+	dummyHash, _ := bcrypt.GenerateFromPassword([]byte("dummy-password"), bcrypt.DefaultCost)
+	return &AuthService{
+		users:     users,
+		cfg:       cfg,
+		dummyHash: dummyHash,
+	}
+}
+
+func convertUserToResponse(user *model.User) UserResponse {
+	return UserResponse{
+		ID:        user.ID,
+		Username:  user.Username,
+		CreatedAt: user.CreatedAt,
+	}
+}
+
+func normalizeUsername(username string) string {
+	return strings.ToLower(strings.TrimSpace(username))
+}
+
+func (s *AuthService) Register(ctx context.Context, username, password string) (_ *SessionResponse, err error) {
+	ctx, span := telemetry.Start(ctx, tracerName, "AuthService.Register")
+	defer func() { telemetry.End(span, err) }()
+
+	if !s.cfg.AllowRegistration {
+		return nil, ErrRegistrationDisabled
+	}
+
+	username = normalizeUsername(username)
+	if !model.IsValidUsername(username) {
+		return nil, fmt.Errorf("%w: %w", ErrInvalidAccount, model.Invalid("username", "username.invalid", model.ErrInvalidUsername, nil))
+	}
+	if err := model.ValidatePassword(password); err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrInvalidAccount, err)
+	}
+
+	hash, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка хэширования пароля: %v", err)
+	}
+
+	id, err := uuid.NewV7()
+	if err != nil {
+		return nil, fmt.Errorf("ошибка генерации UUID v7: %v", err)
+	}
+
+	user := &model.User{
+		ID:           id.String(),
+		Username:     username,
+		PasswordHash: string(hash),
+	}
+	if err := s.users.Create(ctx, user); err != nil {
+		if errors.Is(err, repository.ErrUsernameTaken) {
+			return nil, ErrUsernameTaken
+		}
+		return nil, err
+	}
+
+	slog.InfoContext(ctx, "user registered", slog.String("user_id", user.ID))
+	return s.startSession(ctx, user)
+}
+
+func (s *AuthService) Login(ctx context.Context, username, password string) (_ *SessionResponse, err error) {
+	ctx, span := telemetry.Start(ctx, tracerName, "AuthService.Login")
+	defer func() { telemetry.End(span, err) }()
+
+	user, err := s.users.GetByUsername(ctx, normalizeUsername(username))
+	if err != nil {
+		if errors.Is(err, repository.ErrUserNotFound) {
+			_ = bcrypt.CompareHashAndPassword(s.dummyHash, []byte(password))
+			return nil, ErrInvalidCredentials
+		}
+		return nil, err
+	}
+
+	if err := bcrypt.CompareHashAndPassword([]byte(user.PasswordHash), []byte(password)); err != nil {
+		slog.WarnContext(ctx, "login failed", slog.String("user_id", user.ID))
+		return nil, ErrInvalidCredentials
+	}
+
+	if err := s.users.DeleteExpiredSessions(ctx, user.ID); err != nil {
+		slog.WarnContext(ctx, "expired sessions cleanup failed", slog.String("user_id", user.ID), slog.Any("error", err))
+	}
+
+	return s.startSession(ctx, user)
+}
+
+func (s *AuthService) startSession(ctx context.Context, user *model.User) (*SessionResponse, error) {
+	token, err := generateToken()
+	if err != nil {
+		return nil, fmt.Errorf("ошибка генерации токена: %v", err)
+	}
+
+	session := &model.Session{
+		ID:        lookupHash(token),
+		UserID:    user.ID,
+		ExpiresAt: time.Now().Add(s.cfg.SessionTTL),
+	}
+	if err := s.users.CreateSession(ctx, session); err != nil {
+		return nil, err
+	}
+
+	slog.InfoContext(ctx, "session started", slog.String("user_id", user.ID))
+
+	return &SessionResponse{
+		User:      convertUserToResponse(user),
+		Token:     token,
+		ExpiresAt: session.ExpiresAt,
+	}, nil
+}
+
+func (s *AuthService) Logout(ctx context.Context, token string) (err error) {
+	ctx, span := telemetry.Start(ctx, tracerName, "AuthService.Logout")
+	defer func() { telemetry.End(span, err) }()
+
+	if token == "" {
+		return nil
+	}
+	return s.users.DeleteSession(ctx, lookupHash(token))
+}
+
+// Authenticate находит пользователя по токену сессии из cookie.
+func (s *AuthService) Authenticate(ctx context.Context, token string) (_ *UserResponse, err error) {
+	ctx, span := telemetry.Start(ctx, tracerName, "AuthService.Authenticate")
+	defer func() { telemetry.End(span, err) }()
+
+	if token == "" {
+		return nil, ErrUnauthenticated
+	}
+
+	session, err := s.users.GetSession(ctx, lookupHash(token))
+	if err != nil {
+		if errors.Is(err, repository.ErrSessionNotFound) {
+			return nil, ErrUnauthenticated
+		}
+		return nil, err
+	}
+
+	user, err := s.users.GetByID(ctx, session.UserID)
+	if err != nil {
+		if errors.Is(err, repository.ErrUserNotFound) {
+			return nil, ErrUnauthenticated
+		}
+		return nil, err
+	}
+
+	resp := convertUserToResponse(user)
+	return &resp, nil
+}
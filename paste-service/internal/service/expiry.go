@@ -0,0 +1,57 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"paste-service/internal/telemetry"
+
+	"go.opentelemetry.io/otel/attribute"
+)
+
+// SetExpiry продлевает, сокращает или снимает срок жизни пасты. Новый срок
+// отсчитывается от текущего момента и проходит ту же политику, что и при создании.
+// Как и другие изменения, требует предусловия по версии.
+func (s *PasteService) SetExpiry(ctx context.Context, slug string, editor Editor, match VersionMatch, expiresIn string, expiresAt *time.Time) (_ *PasteResponse, err error) {
+	ctx, span := telemetry.Start(ctx, tracerName, "PasteService.SetExpiry",
+		attribute.String("paste.slug", slug),
+	)
+	defer func() { telemetry.End(span, err) }()
+
+	paste, err := s.repo.GetPasteBySlug(ctx, slug)
+	if err != nil {
+		return nil, mapRepositoryError(err)
+	}
+
+	if _, err := s.authorizeEdit(ctx, slug, editor); err != nil {
+		return nil, err
+	}
+
+	if paste.Locked {
+		return nil, ErrPasteLocked
+	}
+
+	if paste, err = s.checkVersion(ctx, slug, paste, match); err != nil {
+		return nil, err
+	}
+
+	expires, err := s.expiry.Resolve(expiresIn, expiresAt, time.Now(), true)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrInvalidPaste, err)
+	}
+
+	if err := s.repo.SetExpiresAtVersion(ctx, slug, paste.Version, expires); err != nil {
+		return nil, mapRepositoryError(err)
+	}
+	// обновление подняло версию и updated_at, а ETag в ответе должен быть актуальным
+	if paste, err = s.repo.RefreshPaste(ctx, slug); err != nil {
+		return nil, mapRepositoryError(err)
+	}
+
+	slog.InfoContext(ctx, "paste expiry changed", slog.String("slug", slug), slog.Any("expires", expires))
+
+	response := s.convertPasteToResponse(paste)
+	return &response, nil
+}
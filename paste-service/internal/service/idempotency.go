@@ -0,0 +1,225 @@
+package service
+
+import (
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"paste-service/config"
+	"paste-service/internal/model"
+	"paste-service/internal/telemetry"
+	"paste-service/repository"
+)
+
+// pendingTimeout - сколько ждать завершения первого запроса. Дольше висит только
+// запись упавшей реплики, ее можно занять заново.
+const pendingTimeout = time.Minute
+
+const maxIdempotencyKeyLength = 255
+
+var (
+	ErrInvalidIdempotencyKey = errors.New("некорректный Idempotency-Key")
+	ErrIdempotencyKeyReused  = errors.New("Idempotency-Key уже использован с другим запросом")
+	ErrIdempotencyInProgress = errors.New("запрос с этим Idempotency-Key еще выполняется")
+)
+
+// StoredResponse - ответ, который повторяется на ретраи.
+type StoredResponse struct {
+	Status      int
+	ContentType string
+	Headers     map[string]string // заголовки, на которые опираются клиенты
+	Body        []byte
+}
+
+type IdempotencyService struct {
+	repo *repository.IdempotencyRepository
+	cfg  config.IdempotencyConfig
+}
+
+func NewIdempotencyService(repo *repository.IdempotencyRepository, cfg config.IdempotencyConfig) *IdempotencyService {
+	return &IdempotencyService{repo: repo, cfg: cfg}
+}
+
+// recordID привязывает ключ к клиенту: одинаковые ключи разных клиентов не пересекаются.
+func recordID(scope, key string) string {
+	hash := sha256.Sum256([]byte("idempotency:" + scope + "\x00" + key))
+	return hex.EncodeToString(hash[:])
+}
+
+func responseCipher(key string) (cipher.AEAD, error) {
+	secret := sha256.Sum256([]byte("idempotency-response:" + key))
+	block, err := aes.NewCipher(secret[:])
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+func sealResponse(key string, body []byte) ([]byte, error) {
+	aead, err := responseCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	nonce := make([]byte, aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, err
+	}
+	return aead.Seal(nonce, nonce, body, nil), nil
+}
+
+func openResponse(key string, sealed []byte) ([]byte, error) {
+	aead, err := responseCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	if len(sealed) < aead.NonceSize() {
+		return nil, errors.New("поврежденный сохраненный ответ")
+	}
+	nonce, ciphertext := sealed[:aead.NonceSize()], sealed[aead.NonceSize():]
+	return aead.Open(nil, nonce, ciphertext, nil)
+}
+
+func validIdempotencyKey(key string) bool {
+	if key == "" || len(key) > maxIdempotencyKeyLength {
+		return false
+	}
+	for i := 0; i < len(key); i++ {
+		if key[i] < 0x21 || key[i] > 0x7e {
+			return false
+		}
+	}
+	return true
+}
+
+// Begin занимает ключ перед выполнением запроса. Если запрос с этим ключом уже
+// выполнен, возвращает сохраненный ответ, который нужно отдать вместо выполнения.
+func (s *IdempotencyService) Begin(ctx context.Context, scope, key string, requestHash []byte) (_ *StoredResponse, err error) {
+	ctx, span := telemetry.Start(ctx, tracerName, "IdempotencyService.Begin")
+	defer func() { telemetry.End(span, err) }()
+
+	if !validIdempotencyKey(key) {
+		return nil, ErrInvalidIdempotencyKey
+	}
+
+	id := recordID(scope, key)
+	hash := hex.EncodeToString(requestHash)
+
+	// вторая попытка нужна, когда старую запись пришлось удалить
+	for attempt := 0; attempt < 2; attempt++ {
+		err := s.repo.Create(ctx, &model.IdempotencyRecord{
+			ID:          id,
+			RequestHash: hash,
+			ExpiresAt:   time.Now().Add(s.cfg.TTL),
+		})
+		if err == nil {
+			return nil, nil
+		}
+		if !errors.Is(err, repository.ErrIdempotencyRecordExists) {
+			return nil, err
+		}
+
+		record, err := s.repo.Get(ctx, id)
+		if errors.Is(err, repository.ErrIdempotencyRecordNotFound) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		stale := record.IsPending() && time.Since(record.CreatedAt) > pendingTimeout
+		if record.HasExpired() || stale {
+			if err := s.repo.Delete(ctx, id); err != nil {
+				return nil, err
+			}
+			continue
+		}
+
+		if !constantTimeEqual(record.RequestHash, hash) {
+			return nil, ErrIdempotencyKeyReused
+		}
+		if record.IsPending() {
+			return nil, ErrIdempotencyInProgress
+		}
+
+		body, err := openResponse(key, record.Response)
+		if err != nil {
+			return nil, fmt.Errorf("ошибка расшифровки сохраненного ответа: %v", err)
+		}
+
+		var headers map[string]string
+		if record.Headers != "" {
+			if err := json.Unmarshal([]byte(record.Headers), &headers); err != nil {
+				return nil, fmt.Errorf("ошибка разбора сохраненных заголовков: %v", err)
+			}
+		}
+
+		slog.InfoContext(ctx, "idempotent request replayed", slog.Int("status", record.StatusCode))
+		return &StoredResponse{
+			Status:      record.StatusCode,
+			ContentType: record.ContentType,
+			Headers:     headers,
+			Body:        body,
+		}, nil
+	}
+
+	return nil, ErrIdempotencyInProgress
+}
+
+// Complete сохраняет ответ для повторов.
+func (s *IdempotencyService) Complete(ctx context.Context, scope, key string, resp StoredResponse) (err error) {
+	ctx, span := telemetry.Start(ctx, tracerName, "IdempotencyService.Complete")
+	defer func() { telemetry.End(span, err) }()
+
+	sealed, err := sealResponse(key, resp.Body)
+	if err != nil {
+		return err
+	}
+	if resp.Headers == nil {
+		resp.Headers = map[string]string{}
+	}
+	headers, err := json.Marshal(resp.Headers)
+	if err != nil {
+		return err
+	}
+	return s.repo.Complete(ctx, recordID(scope, key), resp.Status, resp.ContentType, string(headers), sealed)
+}
+
+// Abort освобождает ключ, если запрос не удался: ретрай выполнится заново.
+func (s *IdempotencyService) Abort(ctx context.Context, scope, key string) (err error) {
+	ctx, span := telemetry.Start(ctx, tracerName, "IdempotencyService.Abort")
+	defer func() { telemetry.End(span, err) }()
+
+	return s.repo.Delete(ctx, recordID(scope, key))
+}
+
+// RunJanitor периодически удаляет истекшие записи, пока не отменен ctx.
+func (s *IdempotencyService) RunJanitor(ctx context.Context) {
+	if s.cfg.CleanupInterval <= 0 {
+		return
+	}
+	ticker := time.NewTicker(s.cfg.CleanupInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			deleted, err := s.repo.DeleteExpired(ctx)
+			if err != nil {
+				slog.WarnContext(ctx, "idempotency records cleanup failed", slog.Any("error", err))
+				continue
+			}
+			if deleted > 0 {
+				slog.DebugContext(ctx, "idempotency records cleaned up", slog.Int64("deleted", deleted))
+			}
+		case <-ctx.Done():
+			return
+		}
+	}
+}
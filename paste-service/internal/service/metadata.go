@@ -0,0 +1,94 @@
+package service
+
+import (
+	"strings"
+	"unicode/utf8"
+
+	"paste-service/internal/langdetect"
+	"paste-service/internal/model"
+)
+
+// maxInferredTitle - угаданный заголовок короче допустимого: это подпись для листинга,
+// а не вся первая строка лога.
+const maxInferredTitle = 80
+
+// PasteMetadata - описательные поля пасты. nil - поле не меняется. Пустой заголовок
+// или язык означает "угадать по содержимому".
+type PasteMetadata struct {
+	Title       *string
+	Description *string
+	Filename    *string
+	Language    *string
+}
+
+func (m PasteMetadata) fields() []string {
+	var fields []string
+	if m.Title != nil {
+		fields = append(fields, "title")
+	}
+	if m.Description != nil {
+		fields = append(fields, "description")
+	}
+	if m.Filename != nil {
+		fields = append(fields, "filename")
+	}
+	if m.Language != nil {
+		fields = append(fields, "language")
+	}
+	return fields
+}
+
+func applyMetadata(p *model.Paste, m PasteMetadata) {
+	if m.Title != nil {
+		p.Title = strings.TrimSpace(*m.Title)
+		p.TitleInferred = false
+	}
+	if m.Description != nil {
+		p.Description = strings.TrimSpace(*m.Description)
+	}
+	if m.Filename != nil {
+		p.Filename = strings.TrimSpace(*m.Filename)
+	}
+	if m.Language != nil {
+		p.Language = langdetect.Normalize(*m.Language)
+		p.LanguageInferred = false
+	}
+}
+
+// inferMetadata угадывает заголовок и язык, если клиент их не задал. Угаданные
+// значения пересчитываются, заданные клиентом не трогаются.
+func inferMetadata(p *model.Paste) {
+	if p.Title == "" || p.TitleInferred {
+		p.Title = inferTitle(p.Content)
+		p.TitleInferred = p.Title != ""
+	}
+	if p.Language == "" || p.LanguageInferred {
+		p.Language = langdetect.Detect(p.Filename, p.Content)
+		p.LanguageInferred = p.Language != ""
+	}
+}
+
+// inferTitle берет первую непустую строку, кроме шебанга, и обрезает ее до maxInferredTitle символов.
+func inferTitle(content string) string {
+	for len(content) > 0 {
+		var line string
+		line, content, _ = strings.Cut(content, "\n")
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#!") {
+			continue
+		}
+		if !utf8.ValidString(line) {
+			return ""
+		}
+		if utf8.RuneCountInString(line) > maxInferredTitle {
+			cut := 0
+			for i := 0; i < maxInferredTitle-1; i++ {
+				_, size := utf8.DecodeRuneInString(line[cut:])
+				cut += size
+			}
+			line = strings.TrimSpace(line[:cut]) + "…"
+		}
+		return line
+	}
+	return ""
+}
@@ -0,0 +1,109 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+
+	"paste-service/internal/telemetry"
+	"paste-service/repository"
+
+	"go.opentelemetry.io/otel/attribute"
+)
+
+var ErrPasteAlreadyOwned = errors.New("у пасты уже есть владелец")
+
+type PasteList struct {
+	Items []PasteResponse `json:"items"`
+	Total int64           `json:"total"`
+}
+
+// ListUserPastes отдает все пасты пользователя, включая приватные и истекшие.
+func (s *PasteService) ListUserPastes(ctx context.Context, userID string, limit, offset int) (_ *PasteList, err error) {
+	ctx, span := telemetry.Start(ctx, tracerName, "PasteService.ListUserPastes")
+	defer func() { telemetry.End(span, err) }()
+
+	if limit <= 0 || limit > 100 {
+		limit = 20
+	}
+	if offset < 0 {
+		offset = 0
+	}
+
+	pastes, total, err := s.repo.ListByOwner(ctx, userID, limit, offset)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &PasteList{
+		Items: make([]PasteResponse, len(pastes)),
+		Total: total,
+	}
+	for i := range pastes {
+		result.Items[i] = s.convertPasteToResponse(&pastes[i])
+	}
+	return result, nil
+}
+
+// ClaimPaste привязывает анонимную пасту к пользователю, если он знает токен редактирования.
+// Токен после привязки продолжает работать.
+func (s *PasteService) ClaimPaste(ctx context.Context, slug, userID, editToken string) (_ *PasteResponse, err error) {
+	ctx, span := telemetry.Start(ctx, tracerName, "PasteService.ClaimPaste",
+		attribute.String("paste.slug", slug),
+	)
+	defer func() { telemetry.End(span, err) }()
+
+	if _, err := s.repo.GetPasteBySlug(ctx, slug); err != nil {
+		return nil, mapRepositoryError(err)
+	}
+
+	if _, err := s.authorizeEdit(ctx, slug, Editor{EditToken: editToken}); err != nil {
+		return nil, err
+	}
+
+	if err := s.repo.SetOwner(ctx, slug, userID); err != nil {
+		if errors.Is(err, repository.ErrPasteAlreadyOwned) {
+			return nil, ErrPasteAlreadyOwned
+		}
+		return nil, mapRepositoryError(err)
+	}
+
+	paste, err := s.repo.GetPasteBySlug(ctx, slug)
+	if err != nil {
+		return nil, mapRepositoryError(err)
+	}
+
+	slog.InfoContext(ctx, "paste claimed", slog.String("slug", slug), slog.String("user_id", userID))
+
+	response := s.convertPasteToResponse(paste)
+	return &response, nil
+}
+
+// DeletePaste удаляет пасту по решению владельца или держателя токена.
+// Заблокированную модератором пасту удалить нельзя.
+func (s *PasteService) DeletePaste(ctx context.Context, slug string, editor Editor) (err error) {
+	ctx, span := telemetry.Start(ctx, tracerName, "PasteService.DeletePaste",
+		attribute.String("paste.slug", slug),
+	)
+	defer func() { telemetry.End(span, err) }()
+
+	paste, err := s.repo.GetPasteBySlug(ctx, slug)
+	if err != nil {
+		return mapRepositoryError(err)
+	}
+
+	if _, err := s.authorizeEdit(ctx, slug, editor); err != nil {
+		return err
+	}
+
+	if paste.Locked {
+		return ErrPasteLocked
+	}
+
+	if err := s.repo.DeletePaste(ctx, slug); err != nil {
+		return mapRepositoryError(err)
+	}
+
+	slog.InfoContext(ctx, "paste deleted", slog.String("slug", slug), slog.Bool("by_owner", paste.IsOwnedBy(editor.UserID)))
+	return nil
+}
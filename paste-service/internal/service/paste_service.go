@@ -1,22 +1,29 @@
 package service
 
 import (
-	"crypto/rand"
-	"crypto/sha256"
-	"encoding/base64"
-	"encoding/hex"
+	"context"
 	"errors"
 	"fmt"
+	"log/slog"
 	"time"
 
+	"paste-service/config"
 	"paste-service/internal/clients/sluggen"
 	"paste-service/internal/clients/tagger"
+	"paste-service/internal/expiry"
 	"paste-service/internal/model"
+	"paste-service/internal/policy"
+	"paste-service/internal/secrets"
+	"paste-service/internal/sharelink"
+	"paste-service/internal/telemetry"
 	"paste-service/repository"
 
 	"github.com/google/uuid"
+	"go.opentelemetry.io/otel/attribute"
 )
 
+const tracerName = "paste-service/internal/service"
+
 var (
 	ErrInvalidPaste             = errors.New("некорректные данные пасты")
 	ErrPasteNotFound            = errors.New("паста не найдена")
@@ -24,25 +31,43 @@ var (
 	ErrPasteExpired             = errors.New("срок действия пасты истек")
 	ErrTaggerUnavailable        = errors.New("сервис тэггера недоступен")
 	ErrSlugGeneratorUnavailable = errors.New("сервис генерации slug недоступен")
+	ErrPasteLocked              = errors.New("паста заблокирована модератором")
 )
 
 type CreatePasteRequest struct {
-	Content   string         `json:"content"`
-	Tags      []string       `json:"tags,omitempty"`
-	ExpiresIn *time.Duration `json:"expires_in,omitempty"`
-	AutoTag   bool           `json:"auto_tag"`
+	Content    string     `json:"content"`
+	Tags       []string   `json:"tags,omitempty"`
+	ExpiresIn  string     `json:"expires_in,omitempty"` // длительность, пресет или never, см. expiry.Policy
+	ExpiresAt  *time.Time `json:"expires_at,omitempty"`
+	AutoTag    bool       `json:"auto_tag"`
+	Visibility string     `json:"visibility,omitempty"`
+	// заголовок и язык угадываются, если не заданы
+	Title       string `json:"title,omitempty"`
+	Description string `json:"description,omitempty"`
+	Filename    string `json:"filename,omitempty"`
+	Language    string `json:"language,omitempty"`
+	OwnerID     string `json:"-"` // пользователь из сессии, пусто у анонимных
+	APIKeyID    string `json:"-"` // ключ, которым создана паста
+	ClientIP    string `json:"-"` // для квот анонимных клиентов
 }
 
 type PasteResponse struct {
-	ID         string     `json:"id"`
-	Slug       string     `json:"slug"`
-	Content    string     `json:"content"`
-	Tags       []string   `json:"tags"`
-	ViewCount  int        `json:"view_count"`
-	CreatedAt  time.Time  `json:"created_at"`
-	UpdatedAt  time.Time  `json:"updated_at"`
-	LastViewed *time.Time `json:"last_viewed,omitempty"`
-	Expires    *time.Time `json:"expires,omitempty"`
+	ID          string     `json:"id"`
+	Slug        string     `json:"slug"`
+	Content     string     `json:"content"`
+	Tags        []string   `json:"tags"`
+	ViewCount   int        `json:"view_count"`
+	CreatedAt   time.Time  `json:"created_at"`
+	UpdatedAt   time.Time  `json:"updated_at"`
+	LastViewed  *time.Time `json:"last_viewed,omitempty"`
+	Expires     *time.Time `json:"expires,omitempty"`
+	Visibility  string     `json:"visibility"`
+	Version     int64      `json:"version"`
+	Title       string     `json:"title,omitempty"`
+	Description string     `json:"description,omitempty"`
+	Filename    string     `json:"filename,omitempty"`
+	Language    string     `json:"language,omitempty"`
+	Warnings    []Warning  `json:"warnings,omitempty"`
 }
 
 type EditResponse struct {
@@ -50,62 +75,135 @@ type EditResponse struct {
 	EditToken string `json:"edit_token"`
 }
 
+// ReadAccess - чем читатель подтверждает доступ к приватной пасте.
+type ReadAccess struct {
+	UserID     string
+	EditToken  string
+	ShareToken string
+}
+
 type PasteService struct {
 	repo       *repository.PasteRepository
+	shares     *repository.ShareRepository
+	audit      *repository.AuditRepository
 	tagger     tagger.TaggerClient
 	sluggen    sluggen.SlugClient
+	scanner    *secrets.Scanner
+	secretsCfg config.SecretsConfig
+	policy     *policy.Pipeline
+	tokens     *tokenHasher
+	signer     *sharelink.Signer
+	shareCfg   config.ShareConfig
+	quotaCfg   config.QuotaConfig
+	expiry     expiry.Policy
 	maxTagsLen int
 }
 
 func NewPasteService(
 	repo *repository.PasteRepository,
+	shares *repository.ShareRepository,
+	audit *repository.AuditRepository,
 	tagger tagger.TaggerClient,
 	sluggen sluggen.SlugClient,
+	pipeline *policy.Pipeline,
+	signer *sharelink.Signer,
+	cfg *config.Config,
 ) *PasteService {
 	return &PasteService{
 		repo:       repo,
+		shares:     shares,
+		audit:      audit,
 		tagger:     tagger,
 		sluggen:    sluggen,
+		scanner:    secrets.NewScanner(cfg.Secrets.EntropyThreshold),
+		secretsCfg: cfg.Secrets,
+		policy:     pipeline,
+		tokens:     newTokenHasher(cfg.Security.TokenPepper),
+		signer:     signer,
+		shareCfg:   cfg.Share,
+		quotaCfg:   cfg.Quota,
+		expiry:     expiry.NewPolicy(cfg.Expiry),
 		maxTagsLen: 10,
 	}
 }
 
-func generateEditToken() (string, error) {
-	buf := make([]byte, 32)
-	if _, err := rand.Read(buf); err != nil {
-		return "", err
+// mapRepositoryError переводит ошибки репозитория в ошибки сервиса.
+func mapRepositoryError(err error) error {
+	var validationErr *model.ValidationError
+	switch {
+	case errors.As(err, &validationErr):
+		return fmt.Errorf("%w: %w", ErrInvalidPaste, err)
+	case errors.Is(err, repository.ErrPasteNotFound):
+		return ErrPasteNotFound
+	case errors.Is(err, repository.ErrPasteExpired):
+		return ErrPasteExpired
+	case errors.Is(err, repository.ErrVersionConflict):
+		return ErrVersionMismatch
+	default:
+		return err
 	}
-	return hex.EncodeToString(buf), nil
-}
-
-func hashToken(token string) string {
-	hash := sha256.Sum256([]byte(token))
-	return base64.StdEncoding.EncodeToString(hash[:])
-}
-
-func verifyToken(token, hash string) bool {
-	tokenHash := hashToken(token)
-	return tokenHash == hash
 }
 
 func (s *PasteService) convertPasteToResponse(paste *model.Paste) PasteResponse {
 	return PasteResponse{
-		ID:         paste.ID,
-		Slug:       paste.Slug,
-		Content:    paste.Content,
-		Tags:       paste.Tags,
-		ViewCount:  paste.ViewCount,
-		CreatedAt:  paste.CreatedAt,
-		UpdatedAt:  paste.UpdatedAt,
-		LastViewed: paste.LastViewed,
-		Expires:    paste.Expires,
+		ID:          paste.ID,
+		Slug:        paste.Slug,
+		Content:     paste.Content,
+		Tags:        paste.Tags,
+		ViewCount:   paste.ViewCount,
+		CreatedAt:   paste.CreatedAt,
+		UpdatedAt:   paste.UpdatedAt,
+		LastViewed:  paste.LastViewed,
+		Expires:     paste.Expires,
+		Visibility:  paste.Visibility,
+		Version:     paste.Version,
+		Title:       paste.Title,
+		Description: paste.Description,
+		Filename:    paste.Filename,
+		Language:    paste.Language,
 	}
 }
 
-func (s *PasteService) CreatePaste(req CreatePasteRequest) (*EditResponse, error) {
+func (s *PasteService) CreatePaste(ctx context.Context, req CreatePasteRequest) (_ *EditResponse, err error) {
+	ctx, span := telemetry.Start(ctx, tracerName, "PasteService.CreatePaste",
+		attribute.Int("paste.content_size", len(req.Content)),
+		attribute.Bool("paste.auto_tag", req.AutoTag),
+	)
+	defer func() { telemetry.End(span, err) }()
+
 	if req.Content == "" {
-		return nil, ErrInvalidPaste
+		return nil, fmt.Errorf("%w: %w", ErrInvalidPaste, model.Invalid("content", "content.empty", model.ErrEmptyContent, nil))
+	}
+
+	visibility := req.Visibility
+	if visibility == "" {
+		visibility = model.VisibilityPublic
+	}
+	if !model.IsValidVisibility(visibility) {
+		return nil, fmt.Errorf("%w: %w", ErrInvalidPaste, model.Invalid("visibility", "visibility.invalid", model.ErrInvalidVisibility, nil))
+	}
+
+	// срок проверяем до квот и внешних сервисов: ошибка в нем ничего не должна стоить
+	expires, err := s.expiry.Resolve(req.ExpiresIn, req.ExpiresAt, time.Now(), false)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrInvalidPaste, err)
+	}
+
+	creator, tier := QuotaSubject{APIKeyID: req.APIKeyID, UserID: req.OwnerID, ClientIP: req.ClientIP}.creator()
+	size := int64(len(req.Content))
+	if err := s.checkQuota(ctx, creator, tier, true, size, size); err != nil {
+		return nil, err
 	}
+
+	scan, err := s.scanSecrets(req.Content)
+	if err != nil {
+		return nil, err
+	}
+	req.Content = scan.content
+	if scan.forcePrivate {
+		visibility = model.VisibilityPrivate
+	}
+
 	v7Uuid, err := uuid.NewV7()
 	if err != nil {
 		return nil, fmt.Errorf("ошибка генерации UUID v7: %v", err)
@@ -115,7 +213,7 @@ func (s *PasteService) CreatePaste(req CreatePasteRequest) (*EditResponse, error
 	tags := req.Tags
 	if len(tags) == 0 && req.AutoTag {
 		var err error
-		tags, err = s.tagger.GetTags(req.Content)
+		tags, err = s.tagger.GetTags(ctx, req.Content)
 		if err != nil {
 			return nil, fmt.Errorf("%w: %v", ErrTaggerUnavailable, err)
 		}
@@ -125,60 +223,107 @@ func (s *PasteService) CreatePaste(req CreatePasteRequest) (*EditResponse, error
 		tags = tags[:s.maxTagsLen]
 	}
 
-	slug, err := s.sluggen.GenerateSlug(req.Content, tags)
+	if err := s.policy.Evaluate(policy.Input{Content: req.Content, Tags: tags}); err != nil {
+		return nil, err
+	}
+
+	slug, err := s.sluggen.GenerateSlug(ctx, req.Content, tags)
 	if err != nil {
 		return nil, fmt.Errorf("%w: %v", ErrSlugGeneratorUnavailable, err)
 	}
 
-	editToken, err := generateEditToken()
+	editToken, err := generateToken()
 	if err != nil {
 		return nil, fmt.Errorf("ошибка генерации токена: %v", err)
 	}
 
-	hashedToken := hashToken(editToken)
+	hashedToken := s.tokens.hash(editToken)
 
 	now := time.Now()
 	paste := &model.Paste{
-		ID:        id,
-		Slug:      slug,
-		Content:   req.Content,
-		EditToken: hashedToken,
-		Tags:      tags,
-		CreatedAt: now,
-		UpdatedAt: now,
+		ID:         id,
+		Slug:       slug,
+		Content:    req.Content,
+		EditToken:  hashedToken,
+		Tags:       tags,
+		CreatedAt:  now,
+		UpdatedAt:  now,
+		Visibility: visibility,
+		Creator:    creator,
+		Expires:    expires,
 	}
+	applyMetadata(paste, PasteMetadata{
+		Title:       &req.Title,
+		Description: &req.Description,
+		Filename:    &req.Filename,
+		Language:    &req.Language,
+	})
+	inferMetadata(paste)
+	if req.OwnerID != "" {
+		paste.OwnerID = &req.OwnerID
+	}
+	if req.APIKeyID != "" {
+		paste.APIKeyID = &req.APIKeyID
+	}
+
+	span.SetAttributes(attribute.String("paste.slug", slug))
 
-	if req.ExpiresIn != nil {
-		expiresAt := now.Add(*req.ExpiresIn)
-		paste.Expires = &expiresAt
+	if err := s.repo.CreatePaste(ctx, paste, s.quotaCheck(tier, int64(len(paste.Content)))); err != nil {
+		return nil, mapRepositoryError(err)
 	}
 
-	if err := s.repo.CreatePaste(paste); err != nil {
-		return nil, err
+	response := s.convertPasteToResponse(paste)
+	if scan.warning != nil {
+		response.Warnings = append(response.Warnings, *scan.warning)
 	}
 
 	return &EditResponse{
-		PasteResponse: s.convertPasteToResponse(paste),
+		PasteResponse: response,
 		EditToken:     editToken,
 	}, nil
 }
 
-func (s *PasteService) GetPaste(slug string) (*PasteResponse, error) {
-	paste, err := s.repo.GetPasteBySlug(slug)
+// GetPaste отдает пасту и засчитывает просмотр. Приватную пасту читает владелец,
+// держатель токена редактирования или подписанной ссылки, остальным она не видна.
+// Если выполнены условия cond, возвращает notModified без увеличения счетчика:
+// содержимое клиенту не передается, а инструменты, которые перепроверяют пасту
+// по кругу, иначе накручивали бы просмотры.
+func (s *PasteService) GetPaste(ctx context.Context, slug string, access ReadAccess, cond ReadConditions) (_ *PasteResponse, notModified bool, err error) {
+	ctx, span := telemetry.Start(ctx, tracerName, "PasteService.GetPaste",
+		attribute.String("paste.slug", slug),
+	)
+	defer func() { telemetry.End(span, err) }()
+
+	paste, err := s.repo.GetPasteBySlug(ctx, slug)
 	if err != nil {
-		if errors.Is(err, repository.ErrPasteNotFound) {
-			return nil, ErrPasteNotFound
+		return nil, false, mapRepositoryError(err)
+	}
+
+	var shareID string
+	if paste.IsPrivate() {
+		if shareID, err = s.authorizeRead(ctx, paste, access); err != nil {
+			return nil, false, err
 		}
-		if errors.Is(err, repository.ErrPasteExpired) {
-			return nil, ErrPasteExpired
+	}
+
+	// условия проверяем только после авторизации, иначе 304 выдавал бы существование приватной пасты
+	if cond.NotModified(paste.Version, paste.UpdatedAt) {
+		span.SetAttributes(attribute.Bool("paste.not_modified", true))
+		response := s.convertPasteToResponse(paste)
+		return &response, true, nil
+	}
+
+	// просмотр ссылки списываем только сейчас: 304 содержимого не отдает
+	if shareID != "" {
+		if err := s.redeemShare(ctx, slug, shareID, cond.Partial(paste.Version, paste.UpdatedAt)); err != nil {
+			return nil, false, err
 		}
-		return nil, err
 	}
 
-	if err := s.repo.IncrementViewCount(slug); err != nil {
-		fmt.Printf("Ошибка при инкрементировании счетчика просмотров: %v\n", err)
+	if err := s.repo.IncrementViewCount(ctx, slug); err != nil {
+		slog.WarnContext(ctx, "view count increment failed", slog.String("slug", slug), slog.Any("error", err))
 	} else {
-		updatedPaste, err := s.repo.GetPasteBySlug(slug)
+		updatedPaste, err := s.repo.GetPasteBySlug(ctx, slug)
 		if err == nil {
 			paste = updatedPaste
 		} else {
@@ -193,74 +338,19 @@ func (s *PasteService) GetPaste(slug string) (*PasteResponse, error) {
 	}
 
 	response := s.convertPasteToResponse(paste)
-	return &response, nil
+	return &response, false, nil
 }
 
-func (s *PasteService) UpdatePaste(slug, editToken, content string, tags []string) (*PasteResponse, error) {
-	paste, err := s.repo.GetPasteBySlug(slug)
-	if err != nil {
-		if errors.Is(err, repository.ErrPasteNotFound) {
-			return nil, ErrPasteNotFound
-		}
-		if errors.Is(err, repository.ErrPasteExpired) {
-			return nil, ErrPasteExpired
-		}
-		return nil, err
-	}
-
-	if !verifyToken(editToken, paste.EditToken) {
-		return nil, ErrInvalidEditToken
-	}
-
-	paste.Content = content
+func (s *PasteService) UpdatePaste(ctx context.Context, slug string, editor Editor, match VersionMatch, content string, tags []string, meta PasteMetadata) (_ *PasteResponse, err error) {
+	ctx, span := telemetry.Start(ctx, tracerName, "PasteService.UpdatePaste",
+		attribute.String("paste.slug", slug),
+	)
+	defer func() { telemetry.End(span, err) }()
 
+	patch := PastePatch{Content: &content, PasteMetadata: meta}
 	if tags != nil {
-		if len(tags) > s.maxTagsLen {
-			tags = tags[:s.maxTagsLen]
-		}
-		paste.Tags = tags
-	}
-
-	if err := s.repo.UpdatePaste(paste); err != nil {
-		return nil, err
+		patch.Tags = &tags
 	}
-
-	response := s.convertPasteToResponse(paste)
-	return &response, nil
-}
-
-func (s *PasteService) GetTopPastes(limit int) ([]PasteResponse, error) {
-	if limit <= 0 || limit > 100 {
-		limit = 10
-	}
-
-	pastes, err := s.repo.GetTopPastes(limit)
-	if err != nil {
-		return nil, err
-	}
-
-	result := make([]PasteResponse, len(pastes))
-	for i, paste := range pastes {
-		result[i] = s.convertPasteToResponse(&paste)
-	}
-
-	return result, nil
-}
-
-func (s *PasteService) GetRecentPastes(limit int) ([]PasteResponse, error) {
-	if limit <= 0 || limit > 100 {
-		limit = 10
-	}
-
-	pastes, err := s.repo.GetRecentPastes(limit)
-	if err != nil {
-		return nil, err
-	}
-
-	result := make([]PasteResponse, len(pastes))
-	for i, paste := range pastes {
-		result[i] = s.convertPasteToResponse(&paste)
-	}
-
-	return result, nil
+	response, _, err := s.applyPatch(ctx, slug, editor, match, patch)
+	return response, err
 }
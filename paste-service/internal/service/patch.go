@@ -0,0 +1,177 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"paste-service/internal/logging"
+	"paste-service/internal/model"
+	"paste-service/internal/policy"
+	"paste-service/internal/telemetry"
+
+	"go.opentelemetry.io/otel/attribute"
+)
+
+// PastePatch - частичное изменение пасты. nil - поле не меняется.
+type PastePatch struct {
+	Content    *string
+	Tags       *[]string // пустой список очищает теги
+	Visibility *string
+	ExpiresIn  *string // expiry.Never снимает срок
+	ExpiresAt  *time.Time
+	PasteMetadata
+}
+
+// Fields перечисляет изменяемые поля для логов.
+func (p PastePatch) Fields() []string {
+	var fields []string
+	if p.Content != nil {
+		fields = append(fields, "content")
+	}
+	if p.Tags != nil {
+		fields = append(fields, "tags")
+	}
+	if p.Visibility != nil {
+		fields = append(fields, "visibility")
+	}
+	if p.ExpiresIn != nil || p.ExpiresAt != nil {
+		fields = append(fields, "expiry")
+	}
+	return append(fields, p.PasteMetadata.fields()...)
+}
+
+// PatchPaste меняет только переданные поля. Проверки те же, что при создании и PUT.
+func (s *PasteService) PatchPaste(ctx context.Context, slug string, editor Editor, match VersionMatch, patch PastePatch) (_ *PasteResponse, err error) {
+	ctx, span := telemetry.Start(ctx, tracerName, "PasteService.PatchPaste",
+		attribute.String("paste.slug", slug),
+		attribute.StringSlice("patch.fields", patch.Fields()),
+	)
+	defer func() { telemetry.End(span, err) }()
+
+	response, actor, err := s.applyPatch(ctx, slug, editor, match, patch)
+	if err != nil {
+		return nil, err
+	}
+
+	slog.InfoContext(ctx, "paste patched", slog.String("slug", slug), slog.Any("fields", patch.Fields()))
+	// содержимое в журнал не пишем, только какие поля менялись
+	writeAudit(ctx, s.audit, actor, AuditActionPatch, slug, map[string]interface{}{
+		"fields":  patch.Fields(),
+		"version": response.Version,
+	})
+	return response, nil
+}
+
+// applyPatch применяет изменение, только если паста все еще в той версии, которую видел клиент.
+// Вместе с ответом возвращает, кто правил: владелец или держатель токена.
+func (s *PasteService) applyPatch(ctx context.Context, slug string, editor Editor, match VersionMatch, patch PastePatch) (*PasteResponse, Actor, error) {
+	actor := Actor{RemoteIP: editor.RemoteIP, RequestID: logging.RequestID(ctx)}
+
+	paste, err := s.repo.GetPasteBySlug(ctx, slug)
+	if err != nil {
+		return nil, actor, mapRepositoryError(err)
+	}
+
+	if _, err := s.authorizeEdit(ctx, slug, editor); err != nil {
+		return nil, actor, err
+	}
+	actor.Name = "edit_token"
+	if paste.IsOwnedBy(editor.UserID) {
+		actor.Name = "user:" + editor.UserID
+	}
+
+	if paste.Locked {
+		return nil, actor, ErrPasteLocked
+	}
+
+	if paste, err = s.checkVersion(ctx, slug, paste, match); err != nil {
+		return nil, actor, err
+	}
+
+	// у паст, закэшированных до появления видимости, поле пустое
+	if paste.Visibility == "" {
+		paste.Visibility = model.VisibilityPublic
+	}
+	wasPublic := paste.Visibility == model.VisibilityPublic
+	if patch.Visibility != nil {
+		if !model.IsValidVisibility(*patch.Visibility) {
+			return nil, actor, fmt.Errorf("%w: %w", ErrInvalidPaste, model.Invalid("visibility", "visibility.invalid", model.ErrInvalidVisibility, nil))
+		}
+		paste.Visibility = *patch.Visibility
+	}
+
+	if patch.ExpiresIn != nil || patch.ExpiresAt != nil {
+		var expiresIn string
+		if patch.ExpiresIn != nil {
+			expiresIn = *patch.ExpiresIn
+		}
+		expires, err := s.expiry.Resolve(expiresIn, patch.ExpiresAt, time.Now(), true)
+		if err != nil {
+			return nil, actor, fmt.Errorf("%w: %w", ErrInvalidPaste, err)
+		}
+		paste.Expires = expires
+	}
+
+	var warning *Warning
+	if patch.Content != nil {
+		size := int64(len(*patch.Content))
+		if err := s.checkQuota(ctx, paste.Creator, tierForCreator(paste.Creator), false, size, size-int64(len(paste.Content))); err != nil {
+			return nil, actor, err
+		}
+
+		scan, err := s.scanSecrets(*patch.Content)
+		if err != nil {
+			return nil, actor, err
+		}
+		paste.Content = scan.content
+		if scan.forcePrivate {
+			paste.Visibility = model.VisibilityPrivate
+		}
+		warning = scan.warning
+	}
+
+	// новое содержимое проверено выше, а старое могло остаться приватным из-за секретов
+	if patch.Content == nil && !wasPublic && paste.Visibility == model.VisibilityPublic {
+		if err := s.checkPublishable(paste.Content); err != nil {
+			return nil, actor, err
+		}
+	}
+
+	if patch.Tags != nil {
+		tags := *patch.Tags
+		if tags == nil {
+			tags = []string{}
+		}
+		if len(tags) > s.maxTagsLen {
+			tags = tags[:s.maxTagsLen]
+		}
+		paste.Tags = tags
+	}
+
+	applyMetadata(paste, patch.PasteMetadata)
+	if patch.Content != nil || len(patch.PasteMetadata.fields()) > 0 {
+		inferMetadata(paste)
+	}
+
+	// правила смотрят только на содержимое и теги: смена срока или видимости
+	// не должна упираться в правило, добавленное после создания пасты
+	if patch.Content != nil || patch.Tags != nil {
+		if err := s.policy.Evaluate(policy.Input{Content: paste.Content, Tags: paste.Tags}); err != nil {
+			return nil, actor, err
+		}
+	}
+
+	if len(patch.Fields()) > 0 {
+		if err := s.repo.UpdatePaste(ctx, paste); err != nil {
+			return nil, actor, mapRepositoryError(err)
+		}
+	}
+
+	response := s.convertPasteToResponse(paste)
+	if warning != nil {
+		response.Warnings = append(response.Warnings, *warning)
+	}
+	return &response, actor, nil
+}
@@ -0,0 +1,187 @@
+package service
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strings"
+
+	"paste-service/config"
+	"paste-service/internal/telemetry"
+	"paste-service/repository"
+)
+
+const (
+	QuotaTierAnonymous = "anonymous"
+	QuotaTierUser      = "user"
+	QuotaTierAPIKey    = "api_key"
+)
+
+const (
+	QuotaPastesPerDay = "pastes_per_day"
+	QuotaStorageBytes = "storage_bytes"
+	QuotaPasteSize    = "max_paste_size"
+)
+
+var ErrQuotaExceeded = errors.New("квота исчерпана")
+
+// QuotaExceededError говорит, какой именно лимит превышен.
+type QuotaExceededError struct {
+	Quota string `json:"quota"`
+	Limit int64  `json:"limit"`
+	Used  int64  `json:"used"`
+}
+
+func (e *QuotaExceededError) Error() string {
+	return fmt.Sprintf("квота %s исчерпана: %d из %d", e.Quota, e.Used, e.Limit)
+}
+
+func (e *QuotaExceededError) Unwrap() error {
+	return ErrQuotaExceeded
+}
+
+// QuotaSubject - кому засчитывается паста. Ключ важнее учетной записи, учетная запись важнее IP.
+type QuotaSubject struct {
+	APIKeyID string
+	UserID   string
+	ClientIP string
+}
+
+// creator - идентификатор субъекта в колонке pastes.creator. IP не храним в открытом виде.
+func (q QuotaSubject) creator() (creator, tier string) {
+	switch {
+	case q.APIKeyID != "":
+		return "key:" + q.APIKeyID, QuotaTierAPIKey
+	case q.UserID != "":
+		return "user:" + q.UserID, QuotaTierUser
+	case q.ClientIP != "":
+		hash := sha256.Sum256([]byte("quota:" + q.ClientIP))
+		return "ip:" + hex.EncodeToString(hash[:]), QuotaTierAnonymous
+	}
+	return "", QuotaTierAnonymous
+}
+
+// tierForCreator восстанавливает класс клиента по сохраненному creator.
+// У паст, созданных до появления квот, creator пустой - для них действует анонимный класс.
+func tierForCreator(creator string) string {
+	switch {
+	case strings.HasPrefix(creator, "key:"):
+		return QuotaTierAPIKey
+	case strings.HasPrefix(creator, "user:"):
+		return QuotaTierUser
+	}
+	return QuotaTierAnonymous
+}
+
+func (s *PasteService) quotaTier(tier string) config.QuotaTier {
+	switch tier {
+	case QuotaTierAPIKey:
+		return s.quotaCfg.APIKey
+	case QuotaTierUser:
+		return s.quotaCfg.User
+	}
+	return s.quotaCfg.Anonymous
+}
+
+// MaxPasteSize - больше скольких байт содержимого клиенту этого класса принимать
+// незачем: квота все равно отклонит. 0 - квоты выключены или размер не ограничен.
+func (s *PasteService) MaxPasteSize(subject QuotaSubject) int64 {
+	if !s.quotaCfg.Enabled {
+		return 0
+	}
+	_, tier := subject.creator()
+	return s.quotaTier(tier).MaxPasteSize
+}
+
+// checkQuota проверяет размер пасты и, если субъект известен, дневной лимит
+// и объем хранения с учетом прироста sizeDelta.
+func (s *PasteService) checkQuota(ctx context.Context, creator, tier string, newPaste bool, size, sizeDelta int64) error {
+	if !s.quotaCfg.Enabled {
+		return nil
+	}
+
+	limits := s.quotaTier(tier)
+	if limits.MaxPasteSize > 0 && size > limits.MaxPasteSize {
+		return &QuotaExceededError{Quota: QuotaPasteSize, Limit: limits.MaxPasteSize, Used: size}
+	}
+
+	if creator == "" || (limits.PastesPerDay <= 0 && limits.StorageBytes <= 0) {
+		return nil
+	}
+
+	usage, err := s.repo.GetQuotaUsage(ctx, creator, s.quotaCfg.UsageCacheTTL)
+	if err != nil {
+		return err
+	}
+	return checkUsage(limits, usage, newPaste, sizeDelta)
+}
+
+// quotaCheck - повторная проверка лимитов при вставке новой пасты. checkQuota смотрит
+// в кэш и отсекает явное превышение до внешних сервисов, но параллельные создания
+// одного клиента видят там одно и то же; при вставке использование считается заново.
+func (s *PasteService) quotaCheck(tier string, size int64) repository.QuotaCheck {
+	if !s.quotaCfg.Enabled {
+		return nil
+	}
+	limits := s.quotaTier(tier)
+	if limits.PastesPerDay <= 0 && limits.StorageBytes <= 0 {
+		return nil
+	}
+	return func(usage *repository.QuotaUsage) error {
+		return checkUsage(limits, usage, true, size)
+	}
+}
+
+func checkUsage(limits config.QuotaTier, usage *repository.QuotaUsage, newPaste bool, sizeDelta int64) error {
+	if newPaste && limits.PastesPerDay > 0 && usage.PastesLastDay >= limits.PastesPerDay {
+		return &QuotaExceededError{Quota: QuotaPastesPerDay, Limit: limits.PastesPerDay, Used: usage.PastesLastDay}
+	}
+	if sizeDelta > 0 && limits.StorageBytes > 0 && usage.StoredBytes+sizeDelta > limits.StorageBytes {
+		return &QuotaExceededError{Quota: QuotaStorageBytes, Limit: limits.StorageBytes, Used: usage.StoredBytes}
+	}
+	return nil
+}
+
+type QuotaCounter struct {
+	Used  int64 `json:"used"`
+	Limit int64 `json:"limit"` // 0 - без ограничения
+}
+
+type QuotaUsageResponse struct {
+	Enabled      bool         `json:"enabled"`
+	Tier         string       `json:"tier"`
+	Window       string       `json:"window"`
+	PastesPerDay QuotaCounter `json:"pastes_per_day"`
+	StorageBytes QuotaCounter `json:"storage_bytes"`
+	MaxPasteSize int64        `json:"max_paste_size"`
+}
+
+func (s *PasteService) GetQuotaUsage(ctx context.Context, subject QuotaSubject) (_ *QuotaUsageResponse, err error) {
+	ctx, span := telemetry.Start(ctx, tracerName, "PasteService.GetQuotaUsage")
+	defer func() { telemetry.End(span, err) }()
+
+	creator, tier := subject.creator()
+	limits := s.quotaTier(tier)
+
+	resp := &QuotaUsageResponse{
+		Enabled:      s.quotaCfg.Enabled,
+		Tier:         tier,
+		Window:       "24h",
+		PastesPerDay: QuotaCounter{Limit: limits.PastesPerDay},
+		StorageBytes: QuotaCounter{Limit: limits.StorageBytes},
+		MaxPasteSize: limits.MaxPasteSize,
+	}
+	if creator == "" {
+		return resp, nil
+	}
+
+	usage, err := s.repo.GetQuotaUsage(ctx, creator, s.quotaCfg.UsageCacheTTL)
+	if err != nil {
+		return nil, err
+	}
+	resp.PastesPerDay.Used = usage.PastesLastDay
+	resp.StorageBytes.Used = usage.StoredBytes
+	return resp, nil
+}
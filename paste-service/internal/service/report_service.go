@@ -0,0 +1,172 @@
+package service
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strings"
+	"time"
+
+	"paste-service/config"
+	"paste-service/internal/model"
+	"paste-service/internal/telemetry"
+	"paste-service/repository"
+
+	"github.com/google/uuid"
+	"go.opentelemetry.io/otel/attribute"
+)
+
+var (
+	ErrInvalidReport       = errors.New("некорректная жалоба")
+	ErrAlreadyReported     = errors.New("жалоба на эту пасту уже отправлена")
+	ErrReportLimitExceeded = errors.New("слишком много жалоб, попробуйте позже")
+	ErrReportNotFound      = errors.New("жалоба не найдена")
+)
+
+type CreateReportRequest struct {
+	Reason  string `json:"reason"`
+	Comment string `json:"comment,omitempty"`
+}
+
+type ReportResponse struct {
+	ID         string     `json:"id"`
+	PasteSlug  string     `json:"paste_slug"`
+	Reason     string     `json:"reason"`
+	Comment    string     `json:"comment,omitempty"`
+	Status     string     `json:"status"`
+	Resolution string     `json:"resolution,omitempty"`
+	ResolvedBy string     `json:"resolved_by,omitempty"`
+	ResolvedAt *time.Time `json:"resolved_at,omitempty"`
+	CreatedAt  time.Time  `json:"created_at"`
+}
+
+type ReportService struct {
+	repo    *repository.PasteRepository
+	reports *repository.ReportRepository
+	pastes  *PasteService
+	cfg     config.ModerationConfig
+}
+
+func NewReportService(
+	repo *repository.PasteRepository,
+	reports *repository.ReportRepository,
+	pastes *PasteService,
+	cfg config.ModerationConfig,
+) *ReportService {
+	return &ReportService{
+		repo:    repo,
+		reports: reports,
+		pastes:  pastes,
+		cfg:     cfg,
+	}
+}
+
+// reporterHash обезличивает идентификатор клиента, чтобы не хранить IP в открытом виде.
+func reporterHash(reporter string) string {
+	hash := sha256.Sum256([]byte("report:" + reporter))
+	return hex.EncodeToString(hash[:])
+}
+
+func convertReportToResponse(report *model.Report) ReportResponse {
+	return ReportResponse{
+		ID:         report.ID,
+		PasteSlug:  report.PasteSlug,
+		Reason:     report.Reason,
+		Comment:    report.Comment,
+		Status:     report.Status,
+		Resolution: report.Resolution,
+		ResolvedBy: report.ResolvedBy,
+		ResolvedAt: report.ResolvedAt,
+		CreatedAt:  report.CreatedAt,
+	}
+}
+
+// CreateReport сохраняет жалобу и скрывает пасту из листингов, когда набирается
+// порог разных жалоб. reporter - идентификатор клиента (IP). На приватную пасту
+// жалуется только тот, кто может ее прочитать; просмотр ссылки при этом не списывается.
+func (s *ReportService) CreateReport(ctx context.Context, slug, reporter string, access ReadAccess, req CreateReportRequest) (_ *ReportResponse, err error) {
+	ctx, span := telemetry.Start(ctx, tracerName, "ReportService.CreateReport",
+		attribute.String("paste.slug", slug),
+		attribute.String("report.reason", req.Reason),
+	)
+	defer func() { telemetry.End(span, err) }()
+
+	paste, err := s.repo.GetPasteBySlug(ctx, slug)
+	if err != nil {
+		return nil, mapRepositoryError(err)
+	}
+	if paste.IsPrivate() {
+		if _, err := s.pastes.authorizeRead(ctx, paste, access); err != nil {
+			// состояние ссылки тому, кто не может читать пасту, не раскрываем
+			if errors.Is(err, ErrShareLinkExhausted) {
+				return nil, ErrPasteNotFound
+			}
+			return nil, err
+		}
+	}
+
+	hash := reporterHash(reporter)
+
+	if s.cfg.ReportLimit > 0 {
+		count, err := s.reports.CountByReporterSince(ctx, hash, time.Now().Add(-s.cfg.ReportWindow))
+		if err != nil {
+			return nil, err
+		}
+		if count >= int64(s.cfg.ReportLimit) {
+			return nil, ErrReportLimitExceeded
+		}
+	}
+
+	id, err := uuid.NewV7()
+	if err != nil {
+		return nil, fmt.Errorf("ошибка генерации UUID v7: %v", err)
+	}
+
+	report := &model.Report{
+		ID:           id.String(),
+		PasteSlug:    slug,
+		Reason:       strings.ToLower(strings.TrimSpace(req.Reason)),
+		Comment:      strings.TrimSpace(req.Comment),
+		ReporterHash: hash,
+		Status:       model.ReportStatusOpen,
+	}
+
+	if err := s.reports.Create(ctx, report); err != nil {
+		switch {
+		case errors.Is(err, repository.ErrAlreadyReported):
+			return nil, ErrAlreadyReported
+		case errors.Is(err, model.ErrInvalidReportReason), errors.Is(err, model.ErrReportCommentTooLong):
+			return nil, fmt.Errorf("%w: %w", ErrInvalidReport, err)
+		}
+		return nil, err
+	}
+
+	s.hideIfThresholdReached(ctx, slug)
+
+	resp := convertReportToResponse(report)
+	return &resp, nil
+}
+
+func (s *ReportService) hideIfThresholdReached(ctx context.Context, slug string) {
+	if s.cfg.HideThreshold <= 0 {
+		return
+	}
+
+	count, err := s.reports.CountOpenForPaste(ctx, slug)
+	if err != nil {
+		slog.ErrorContext(ctx, "open reports count failed", slog.String("slug", slug), slog.Any("error", err))
+		return
+	}
+	if count < int64(s.cfg.HideThreshold) {
+		return
+	}
+
+	if err := s.repo.SetHidden(ctx, slug, true); err != nil {
+		slog.ErrorContext(ctx, "paste auto-hide failed", slog.String("slug", slug), slog.Any("error", err))
+		return
+	}
+	slog.InfoContext(ctx, "paste hidden by reports", slog.String("slug", slug), slog.Int64("open_reports", count))
+}
@@ -0,0 +1,90 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+
+	"paste-service/internal/secrets"
+)
+
+var ErrSecretsDetected = errors.New("в содержимом пасты найдены секреты")
+
+const WarningSecretsDetected = "secrets_detected"
+
+// Warning - предупреждение, которое возвращается вместе с успешным ответом.
+// Message заполняет транспорт: переводит MessageKey на язык клиента.
+type Warning struct {
+	Code       string            `json:"code"`
+	Message    string            `json:"message"`
+	MessageKey string            `json:"-"`
+	Action     string            `json:"action,omitempty"`
+	Findings   []secrets.Finding `json:"findings,omitempty"`
+}
+
+// SecretsDetectedError возвращается в режиме reject и несет список находок.
+type SecretsDetectedError struct {
+	Findings []secrets.Finding
+}
+
+func (e *SecretsDetectedError) Error() string {
+	return fmt.Sprintf("%v: %d", ErrSecretsDetected, len(e.Findings))
+}
+
+func (e *SecretsDetectedError) Unwrap() error {
+	return ErrSecretsDetected
+}
+
+type secretScanResult struct {
+	content      string
+	forcePrivate bool
+	warning      *Warning
+}
+
+// scanSecrets применяет политику из конфига к содержимому. Вызывается до того,
+// как содержимое уйдет в тэггер и генератор slug.
+func (s *PasteService) scanSecrets(content string) (*secretScanResult, error) {
+	result := &secretScanResult{content: content}
+	if !s.secretsCfg.Enabled {
+		return result, nil
+	}
+
+	findings := s.scanner.Scan(content)
+	if len(findings) == 0 {
+		return result, nil
+	}
+
+	switch s.secretsCfg.Mode {
+	case secrets.ModeReject:
+		return nil, &SecretsDetectedError{Findings: findings}
+	case secrets.ModeRedact:
+		result.content = secrets.Redact(content, findings)
+		result.warning = &Warning{
+			Code:       WarningSecretsDetected,
+			MessageKey: "paste.secrets_redacted",
+			Action:     secrets.ModeRedact,
+			Findings:   findings,
+		}
+	default:
+		result.forcePrivate = true
+		result.warning = &Warning{
+			Code:       WarningSecretsDetected,
+			MessageKey: "paste.secrets_private",
+			Action:     secrets.ModePrivate,
+			Findings:   findings,
+		}
+	}
+
+	return result, nil
+}
+
+// checkPublishable не дает открыть пасту, в которой остались секреты: в режиме private
+// такую пасту сделали приватной при создании, и смена видимости не должна это обойти.
+func (s *PasteService) checkPublishable(content string) error {
+	if !s.secretsCfg.Enabled {
+		return nil
+	}
+	if findings := s.scanner.Scan(content); len(findings) > 0 {
+		return &SecretsDetectedError{Findings: findings}
+	}
+	return nil
+}
@@ -0,0 +1,224 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"paste-service/internal/model"
+	"paste-service/internal/sharelink"
+	"paste-service/internal/telemetry"
+	"paste-service/repository"
+
+	"github.com/google/uuid"
+	"go.opentelemetry.io/otel/attribute"
+)
+
+var (
+	ErrSharingDisabled    = errors.New("ссылки для чтения не настроены")
+	ErrInvalidShareLink   = errors.New("некорректные параметры ссылки")
+	ErrShareLinkNotFound  = errors.New("ссылка не найдена")
+	ErrShareLinkExhausted = errors.New("ссылка отозвана, истекла или исчерпала лимит просмотров")
+
+	errShareTTLOutOfRange = errors.New("срок действия ссылки вне допустимого диапазона")
+	errNegativeMaxViews   = errors.New("max_views не может быть отрицательным")
+)
+
+type CreateShareLinkRequest struct {
+	ExpiresIn *time.Duration
+	MaxViews  int
+}
+
+type ShareLinkResponse struct {
+	ID        string     `json:"id"`
+	Token     string     `json:"token,omitempty"`
+	ExpiresAt time.Time  `json:"expires_at"`
+	MaxViews  int        `json:"max_views,omitempty"`
+	Views     int        `json:"views"`
+	Revoked   bool       `json:"revoked"`
+	RevokedAt *time.Time `json:"revoked_at,omitempty"`
+	CreatedAt time.Time  `json:"created_at"`
+}
+
+func convertShareLinkToResponse(link *model.ShareLink) ShareLinkResponse {
+	return ShareLinkResponse{
+		ID:        link.ID,
+		ExpiresAt: link.ExpiresAt,
+		MaxViews:  link.MaxViews,
+		Views:     link.Views,
+		Revoked:   link.Revoked,
+		RevokedAt: link.RevokedAt,
+		CreatedAt: link.CreatedAt,
+	}
+}
+
+// authorizeRead пускает к приватной пасте владельца, держателя токена редактирования
+// или подписанной ссылки. Для ссылки возвращает ее ID, но просмотр не списывает: это
+// делает redeemShare, когда содержимое действительно отдается.
+// Ссылка с чужой или битой подписью неотличима от отсутствующей пасты.
+func (s *PasteService) authorizeRead(ctx context.Context, paste *model.Paste, access ReadAccess) (shareID string, err error) {
+	if paste.IsOwnedBy(access.UserID) {
+		return "", nil
+	}
+	if access.EditToken != "" {
+		// хэш берем из БД, как при правке: после ротации копия в кэше другой
+		// реплики еще хранит старый и пускала бы по отозванному токену
+		stored, err := s.repo.GetEditTokenHash(ctx, paste.Slug)
+		if err != nil {
+			return "", mapRepositoryError(err)
+		}
+		if ok, _ := s.tokens.verify(access.EditToken, stored); ok {
+			return "", nil
+		}
+	}
+
+	if access.ShareToken == "" || !s.signer.Enabled() {
+		return "", ErrPasteNotFound
+	}
+
+	claims, err := s.signer.Verify(access.ShareToken, time.Now())
+	if err != nil {
+		if errors.Is(err, sharelink.ErrExpired) {
+			return "", ErrShareLinkExhausted
+		}
+		return "", ErrPasteNotFound
+	}
+	if claims.Slug != paste.Slug {
+		return "", ErrPasteNotFound
+	}
+
+	if err := s.shares.Check(ctx, paste.Slug, claims.ID, false); err != nil {
+		return "", mapShareError(err)
+	}
+	return claims.ID, nil
+}
+
+// redeemShare списывает просмотр ссылки. Часть содержимого (Range) просмотра не тратит,
+// если ссылку уже открывали: иначе докачка файла съедала бы лимит получателя.
+func (s *PasteService) redeemShare(ctx context.Context, slug, id string, partial bool) error {
+	if partial {
+		return mapShareError(s.shares.Check(ctx, slug, id, true))
+	}
+	return mapShareError(s.shares.Redeem(ctx, slug, id))
+}
+
+func mapShareError(err error) error {
+	if errors.Is(err, repository.ErrShareLinkExhausted) {
+		return ErrShareLinkExhausted
+	}
+	return err
+}
+
+// CreateShareLink выпускает подписанную ссылку на чтение одной пасты.
+func (s *PasteService) CreateShareLink(ctx context.Context, slug string, editor Editor, req CreateShareLinkRequest) (_ *ShareLinkResponse, err error) {
+	ctx, span := telemetry.Start(ctx, tracerName, "PasteService.CreateShareLink",
+		attribute.String("paste.slug", slug),
+	)
+	defer func() { telemetry.End(span, err) }()
+
+	if !s.signer.Enabled() {
+		return nil, ErrSharingDisabled
+	}
+
+	ttl := s.shareCfg.DefaultTTL
+	if req.ExpiresIn != nil {
+		ttl = *req.ExpiresIn
+	}
+	if ttl <= 0 || (s.shareCfg.MaxTTL > 0 && ttl > s.shareCfg.MaxTTL) {
+		return nil, fmt.Errorf("%w: %w", ErrInvalidShareLink, model.Invalid("expires_in", "expires_in.out_of_range", errShareTTLOutOfRange,
+			map[string]any{"max": s.shareCfg.MaxTTL.String()}))
+	}
+	if req.MaxViews < 0 {
+		return nil, fmt.Errorf("%w: %w", ErrInvalidShareLink, model.Invalid("max_views", "max_views.negative", errNegativeMaxViews, nil))
+	}
+
+	paste, err := s.repo.GetPasteBySlug(ctx, slug)
+	if err != nil {
+		return nil, mapRepositoryError(err)
+	}
+	if _, err := s.authorizeEdit(ctx, slug, editor); err != nil {
+		return nil, err
+	}
+
+	expiresAt := time.Now().Add(ttl)
+	// ссылка не переживает саму пасту
+	if paste.Expires != nil && paste.Expires.Before(expiresAt) {
+		expiresAt = *paste.Expires
+	}
+
+	id, err := uuid.NewV7()
+	if err != nil {
+		return nil, fmt.Errorf("ошибка генерации UUID v7: %v", err)
+	}
+
+	link := &model.ShareLink{
+		ID:        id.String(),
+		PasteSlug: slug,
+		ExpiresAt: expiresAt,
+		MaxViews:  req.MaxViews,
+	}
+	if err := s.shares.Create(ctx, link); err != nil {
+		return nil, err
+	}
+
+	token, err := s.signer.Sign(link.ID, slug, expiresAt)
+	if err != nil {
+		return nil, err
+	}
+
+	slog.InfoContext(ctx, "share link created",
+		slog.String("slug", slug),
+		slog.String("link_id", link.ID),
+		slog.Time("expires_at", expiresAt),
+		slog.Int("max_views", req.MaxViews),
+	)
+
+	resp := convertShareLinkToResponse(link)
+	resp.Token = token
+	return &resp, nil
+}
+
+func (s *PasteService) ListShareLinks(ctx context.Context, slug string, editor Editor) (_ []ShareLinkResponse, err error) {
+	ctx, span := telemetry.Start(ctx, tracerName, "PasteService.ListShareLinks",
+		attribute.String("paste.slug", slug),
+	)
+	defer func() { telemetry.End(span, err) }()
+
+	if _, err := s.authorizeEdit(ctx, slug, editor); err != nil {
+		return nil, err
+	}
+
+	links, err := s.shares.ListForPaste(ctx, slug)
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]ShareLinkResponse, len(links))
+	for i := range links {
+		result[i] = convertShareLinkToResponse(&links[i])
+	}
+	return result, nil
+}
+
+func (s *PasteService) RevokeShareLink(ctx context.Context, slug string, editor Editor, id string) (err error) {
+	ctx, span := telemetry.Start(ctx, tracerName, "PasteService.RevokeShareLink",
+		attribute.String("paste.slug", slug),
+	)
+	defer func() { telemetry.End(span, err) }()
+
+	if _, err := s.authorizeEdit(ctx, slug, editor); err != nil {
+		return err
+	}
+
+	if err := s.shares.Revoke(ctx, slug, id); err != nil {
+		if errors.Is(err, repository.ErrShareLinkNotFound) {
+			return ErrShareLinkNotFound
+		}
+		return err
+	}
+
+	slog.InfoContext(ctx, "share link revoked", slog.String("slug", slug), slog.String("link_id", id))
+	return nil
+}
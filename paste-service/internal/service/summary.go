@@ -0,0 +1,108 @@
+package service
+
+import (
+	"context"
+	"time"
+	"unicode/utf8"
+
+	"paste-service/internal/telemetry"
+	"paste-service/repository"
+
+	"go.opentelemetry.io/otel/attribute"
+)
+
+// SummaryFields - что можно добавить к листингу через ?fields=.
+var SummaryFields = repository.SummaryFields
+
+// PasteSummary - паста в листинге: вместо содержимого превью и его размеры.
+// Поля после CreatedAt приходят, только если их запросили в fields.
+type PasteSummary struct {
+	ID        string    `json:"id"`
+	Slug      string    `json:"slug"`
+	Title     string    `json:"title,omitempty"`
+	Language  string    `json:"language,omitempty"`
+	Tags      []string  `json:"tags"`
+	Preview   string    `json:"preview"`
+	Truncated bool      `json:"truncated"` // превью короче содержимого
+	LineCount int       `json:"line_count"`
+	Size      int64     `json:"size"` // в байтах
+	ViewCount int       `json:"view_count"`
+	CreatedAt time.Time `json:"created_at"`
+
+	Content     *string    `json:"content,omitempty"`
+	Description *string    `json:"description,omitempty"`
+	Filename    *string    `json:"filename,omitempty"`
+	UpdatedAt   *time.Time `json:"updated_at,omitempty"`
+	LastViewed  *time.Time `json:"last_viewed,omitempty"`
+	Expires     *time.Time `json:"expires,omitempty"`
+	Version     *int64     `json:"version,omitempty"`
+}
+
+func (s *PasteService) GetTopPastes(ctx context.Context, limit int, fields []string) (_ []PasteSummary, err error) {
+	ctx, span := telemetry.Start(ctx, tracerName, "PasteService.GetTopPastes",
+		attribute.StringSlice("query.fields", fields),
+	)
+	defer func() { telemetry.End(span, err) }()
+
+	pastes, err := s.repo.GetTopPastes(ctx, listLimit(limit), fields)
+	if err != nil {
+		return nil, err
+	}
+	return convertSummaries(pastes), nil
+}
+
+func (s *PasteService) GetRecentPastes(ctx context.Context, limit int, fields []string) (_ []PasteSummary, err error) {
+	ctx, span := telemetry.Start(ctx, tracerName, "PasteService.GetRecentPastes",
+		attribute.StringSlice("query.fields", fields),
+	)
+	defer func() { telemetry.End(span, err) }()
+
+	pastes, err := s.repo.GetRecentPastes(ctx, listLimit(limit), fields)
+	if err != nil {
+		return nil, err
+	}
+	return convertSummaries(pastes), nil
+}
+
+func listLimit(limit int) int {
+	if limit <= 0 || limit > 100 {
+		return 10
+	}
+	return limit
+}
+
+func convertSummaries(pastes []repository.PasteSummary) []PasteSummary {
+	result := make([]PasteSummary, len(pastes))
+	for i, p := range pastes {
+		preview, truncated := p.Preview, false
+		if utf8.RuneCountInString(preview) > repository.PreviewLength {
+			preview = string([]rune(preview)[:repository.PreviewLength])
+			truncated = true
+		}
+		tags := p.Tags
+		if tags == nil {
+			tags = []string{}
+		}
+		result[i] = PasteSummary{
+			ID:          p.ID,
+			Slug:        p.Slug,
+			Title:       p.Title,
+			Language:    p.Language,
+			Tags:        tags,
+			Preview:     preview,
+			Truncated:   truncated,
+			LineCount:   p.LineCount,
+			Size:        p.Size,
+			ViewCount:   p.ViewCount,
+			CreatedAt:   p.CreatedAt,
+			Content:     p.Content,
+			Description: p.Description,
+			Filename:    p.Filename,
+			UpdatedAt:   p.UpdatedAt,
+			LastViewed:  p.LastViewed,
+			Expires:     p.Expires,
+			Version:     p.Version,
+		}
+	}
+	return result
+}
@@ -0,0 +1,168 @@
+package service
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strings"
+
+	"paste-service/internal/telemetry"
+	"paste-service/repository"
+
+	"go.opentelemetry.io/otel/attribute"
+)
+
+// hmacTokenPrefix отличает новые хэши от старых несоленых sha256.
+const hmacTokenPrefix = "v2:"
+
+func generateToken() (string, error) {
+	buf := make([]byte, 32)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(buf), nil
+}
+
+// lookupHash - хэш случайного токена для поиска в БД (сессии, API-ключи).
+// Токены длинные и случайные, перец и медленный хэш им не нужны,
+// а по утечке таблицы войти все равно нельзя.
+func lookupHash(token string) string {
+	hash := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(hash[:])
+}
+
+// tokenHasher хэширует токены редактирования через HMAC-SHA256 с серверным перцем,
+// поэтому утечка таблицы без перца не позволяет подобрать токены.
+type tokenHasher struct {
+	pepper []byte
+}
+
+func newTokenHasher(pepper string) *tokenHasher {
+	return &tokenHasher{pepper: []byte(pepper)}
+}
+
+func (h *tokenHasher) hash(token string) string {
+	mac := hmac.New(sha256.New, h.pepper)
+	mac.Write([]byte(token))
+	return hmacTokenPrefix + base64.StdEncoding.EncodeToString(mac.Sum(nil))
+}
+
+// legacyHash - формат до перехода на HMAC, нужен только для проверки старых паст.
+func legacyHash(token string) string {
+	hash := sha256.Sum256([]byte(token))
+	return base64.StdEncoding.EncodeToString(hash[:])
+}
+
+// verify сравнивает токен с сохраненным хэшем за постоянное время.
+// needsUpgrade означает, что хэш в старом формате и его стоит перезаписать.
+func (h *tokenHasher) verify(token, stored string) (ok, needsUpgrade bool) {
+	if token == "" || stored == "" {
+		return false, false
+	}
+
+	if strings.HasPrefix(stored, hmacTokenPrefix) {
+		return constantTimeEqual(h.hash(token), stored), false
+	}
+
+	ok = constantTimeEqual(legacyHash(token), stored)
+	return ok, ok
+}
+
+func constantTimeEqual(a, b string) bool {
+	return subtle.ConstantTimeCompare([]byte(a), []byte(b)) == 1
+}
+
+type RotateTokenResponse struct {
+	Slug      string `json:"slug"`
+	EditToken string `json:"edit_token"`
+}
+
+// Editor - кто пытается изменить пасту: владелец по сессии или держатель токена.
+type Editor struct {
+	UserID    string
+	EditToken string
+	RemoteIP  string // для журнала аудита
+}
+
+// authorizeEdit пускает владельца пасты без токена, остальных - по токену.
+// Возвращает актуальный хэш токена из БД. Старые sha256-хэши при успешной
+// проверке прозрачно перезаписываются на HMAC.
+func (s *PasteService) authorizeEdit(ctx context.Context, slug string, editor Editor) (string, error) {
+	stored, err := s.repo.GetEditTokenHash(ctx, slug)
+	if err != nil {
+		return "", mapRepositoryError(err)
+	}
+
+	if editor.UserID != "" {
+		paste, err := s.repo.GetPasteBySlug(ctx, slug)
+		if err != nil {
+			return "", mapRepositoryError(err)
+		}
+		if paste.IsOwnedBy(editor.UserID) {
+			return stored, nil
+		}
+	}
+
+	editToken := editor.EditToken
+	ok, needsUpgrade := s.tokens.verify(editToken, stored)
+	if !ok {
+		return "", ErrInvalidEditToken
+	}
+	if !needsUpgrade {
+		return stored, nil
+	}
+
+	upgraded := s.tokens.hash(editToken)
+	if err := s.repo.SetEditTokenHash(ctx, slug, upgraded, stored); err != nil {
+		// не критично: проверка уже пройдена, перезапишем в следующий раз
+		slog.WarnContext(ctx, "edit token hash upgrade failed", slog.String("slug", slug), slog.Any("error", err))
+		return stored, nil
+	}
+	slog.InfoContext(ctx, "edit token hash upgraded", slog.String("slug", slug))
+	return upgraded, nil
+}
+
+// RotateEditToken выдает новый токен редактирования. Старый перестает
+// действовать сразу: хэш меняется в БД, запись в кэше сбрасывается.
+func (s *PasteService) RotateEditToken(ctx context.Context, slug string, editor Editor) (_ *RotateTokenResponse, err error) {
+	ctx, span := telemetry.Start(ctx, tracerName, "PasteService.RotateEditToken",
+		attribute.String("paste.slug", slug),
+	)
+	defer func() { telemetry.End(span, err) }()
+
+	if _, err := s.repo.GetPasteBySlug(ctx, slug); err != nil {
+		return nil, mapRepositoryError(err)
+	}
+
+	currentHash, err := s.authorizeEdit(ctx, slug, editor)
+	if err != nil {
+		return nil, err
+	}
+
+	newToken, err := generateToken()
+	if err != nil {
+		return nil, fmt.Errorf("ошибка генерации токена: %v", err)
+	}
+
+	if err := s.repo.SetEditTokenHash(ctx, slug, s.tokens.hash(newToken), currentHash); err != nil {
+		if errors.Is(err, repository.ErrEditTokenChanged) {
+			// токен успели сменить параллельным запросом
+			return nil, ErrInvalidEditToken
+		}
+		return nil, mapRepositoryError(err)
+	}
+
+	slog.InfoContext(ctx, "edit token rotated", slog.String("slug", slug))
+
+	return &RotateTokenResponse{
+		Slug:      slug,
+		EditToken: newToken,
+	}, nil
+}
@@ -0,0 +1,93 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"paste-service/internal/model"
+)
+
+var (
+	ErrPreconditionRequired = errors.New("для изменения пасты нужен If-Match или version")
+	ErrVersionMismatch      = errors.New("паста изменена с момента чтения")
+)
+
+// VersionMatch - предусловие изменения из If-Match или поля version.
+type VersionMatch struct {
+	Present  bool // клиент передал предусловие
+	Any      bool // If-Match: *
+	Versions []int64
+}
+
+func (m VersionMatch) Matches(version int64) bool {
+	if m.Any {
+		return true
+	}
+	for _, v := range m.Versions {
+		if v == version {
+			return true
+		}
+	}
+	return false
+}
+
+// ReadConditions - условные заголовки чтения: If-None-Match и If-Modified-Since,
+// а для текста еще Range и If-Range.
+type ReadConditions struct {
+	IfNoneMatch     VersionMatch
+	IfModifiedSince *time.Time
+	Range           bool
+	IfRange         VersionMatch
+	IfRangeTime     *time.Time
+}
+
+// NotModified сообщает, что у клиента актуальная копия. If-None-Match важнее
+// If-Modified-Since (RFC 9110, 13.2.2), даты сравниваются с точностью до секунды.
+func (c ReadConditions) NotModified(version int64, updatedAt time.Time) bool {
+	if c.IfNoneMatch.Present {
+		return c.IfNoneMatch.Matches(version)
+	}
+	if c.IfModifiedSince != nil {
+		return !updatedAt.Truncate(time.Second).After(*c.IfModifiedSince)
+	}
+	return false
+}
+
+// Partial сообщает, что клиент получит часть содержимого: Range есть, а If-Range
+// либо не передан, либо совпал с текущей версией (иначе отдается все целиком).
+func (c ReadConditions) Partial(version int64, updatedAt time.Time) bool {
+	if !c.Range {
+		return false
+	}
+	if c.IfRange.Present {
+		return c.IfRange.Matches(version)
+	}
+	if c.IfRangeTime != nil {
+		return updatedAt.Truncate(time.Second).Equal(*c.IfRangeTime)
+	}
+	return true
+}
+
+// checkVersion проверяет предусловие изменения и возвращает пасту, с которой сравнивали.
+// Копия в кэше могла отстать от БД, поэтому при несовпадении решает только свежая версия.
+func (s *PasteService) checkVersion(ctx context.Context, slug string, paste *model.Paste, match VersionMatch) (*model.Paste, error) {
+	if !match.Present {
+		return nil, ErrPreconditionRequired
+	}
+	if match.Matches(paste.Version) {
+		return paste, nil
+	}
+
+	paste, err := s.repo.RefreshPaste(ctx, slug)
+	if err != nil {
+		return nil, mapRepositoryError(err)
+	}
+	if !match.Matches(paste.Version) {
+		return nil, ErrVersionMismatch
+	}
+	if paste.Locked {
+		return nil, ErrPasteLocked
+	}
+	return paste, nil
+}
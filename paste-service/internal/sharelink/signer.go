@@ -0,0 +1,123 @@
+package sharelink
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+var (
+	ErrNoKeys         = errors.New("не заданы ключи подписи ссылок")
+	ErrMalformedToken = errors.New("некорректный токен ссылки")
+	ErrBadSignature   = errors.New("неверная подпись ссылки")
+	ErrUnknownKey     = errors.New("неизвестный ключ подписи ссылки")
+	ErrExpired        = errors.New("срок действия ссылки истек")
+)
+
+// Claims - подписанная часть ссылки. Лимит просмотров и отзыв хранятся в БД по ID.
+type Claims struct {
+	ID        string `json:"id"`
+	Slug      string `json:"s"`
+	ExpiresAt int64  `json:"e"`
+	KeyID     string `json:"k"`
+}
+
+// Signer подписывает ссылки активным ключом (первым в списке) и принимает
+// подписи всех перечисленных ключей, так ключ можно ротировать без поломки выданных ссылок.
+type Signer struct {
+	keys     map[string][]byte
+	activeID string
+}
+
+// NewSigner принимает ключи в формате "kid:secret".
+func NewSigner(keys []string) (*Signer, error) {
+	s := &Signer{keys: make(map[string][]byte)}
+	for _, raw := range keys {
+		raw = strings.TrimSpace(raw)
+		if raw == "" {
+			continue
+		}
+		kid, secret, ok := strings.Cut(raw, ":")
+		if !ok || kid == "" || secret == "" {
+			return nil, fmt.Errorf("ключ подписи должен быть в формате kid:secret")
+		}
+		if _, dup := s.keys[kid]; dup {
+			return nil, fmt.Errorf("ключ подписи %q указан дважды", kid)
+		}
+		s.keys[kid] = []byte(secret)
+		if s.activeID == "" {
+			s.activeID = kid
+		}
+	}
+	return s, nil
+}
+
+func (s *Signer) Enabled() bool {
+	return s != nil && s.activeID != ""
+}
+
+func (s *Signer) Sign(id, slug string, expiresAt time.Time) (string, error) {
+	if !s.Enabled() {
+		return "", ErrNoKeys
+	}
+
+	payload, err := json.Marshal(Claims{
+		ID:        id,
+		Slug:      slug,
+		ExpiresAt: expiresAt.Unix(),
+		KeyID:     s.activeID,
+	})
+	if err != nil {
+		return "", err
+	}
+
+	encoded := base64.RawURLEncoding.EncodeToString(payload)
+	return encoded + "." + s.signature(s.keys[s.activeID], encoded), nil
+}
+
+// Verify проверяет подпись и срок действия. Отзыв и лимит просмотров проверяет вызывающий.
+func (s *Signer) Verify(token string, now time.Time) (*Claims, error) {
+	if !s.Enabled() {
+		return nil, ErrNoKeys
+	}
+
+	encoded, sig, ok := strings.Cut(token, ".")
+	if !ok {
+		return nil, ErrMalformedToken
+	}
+
+	payload, err := base64.RawURLEncoding.DecodeString(encoded)
+	if err != nil {
+		return nil, ErrMalformedToken
+	}
+
+	var claims Claims
+	if err := json.Unmarshal(payload, &claims); err != nil {
+		return nil, ErrMalformedToken
+	}
+
+	key, ok := s.keys[claims.KeyID]
+	if !ok {
+		return nil, ErrUnknownKey
+	}
+	if !hmac.Equal([]byte(sig), []byte(s.signature(key, encoded))) {
+		return nil, ErrBadSignature
+	}
+
+	if now.Unix() >= claims.ExpiresAt {
+		return nil, ErrExpired
+	}
+
+	return &claims, nil
+}
+
+func (s *Signer) signature(key []byte, payload string) string {
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(payload))
+	return base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
@@ -0,0 +1,352 @@
+// Package tcppaste - прием паст по голому TCP в духе termbin: `cmd | nc host 9999`.
+// Клиент присылает содержимое и закрывает запись (или замолкает на IdleTimeout),
+// в ответ получает ссылку на пасту и токен редактирования.
+package tcppaste
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"net"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+	"unicode/utf8"
+
+	"paste-service/config"
+	"paste-service/internal/i18n"
+	"paste-service/internal/model"
+	"paste-service/internal/policy"
+	"paste-service/internal/ratelimit"
+	"paste-service/internal/service"
+	"paste-service/internal/telemetry"
+
+	"go.opentelemetry.io/otel/attribute"
+)
+
+const tracerName = "paste-service/internal/tcppaste"
+
+// writeTimeout - ответ в пару строк, долго ждать медленного клиента незачем.
+const writeTimeout = 10 * time.Second
+
+var ErrServerClosed = errors.New("tcp-сервер остановлен")
+
+type Server struct {
+	service *service.PasteService
+	limiter ratelimit.Store
+	limit   ratelimit.Limit
+	cfg     config.TCPConfig
+	// для ссылок: SERVER_PUBLICURL или адрес, на который пришло соединение, и HTTP-порт
+	publicURL string
+	httpPort  string
+	// без квот чтение ограничено тем же размером, что и тело HTTP-запроса
+	maxRequestSize int64
+
+	mu       sync.Mutex
+	listener net.Listener
+	conns    map[net.Conn]struct{}
+	closed   bool
+	wg       sync.WaitGroup
+	slots    chan struct{}
+}
+
+func NewServer(service *service.PasteService, limiter ratelimit.Store, cfg *config.Config) *Server {
+	return &Server{
+		service:        service,
+		limiter:        limiter,
+		limit:          ratelimit.PerMinute(cfg.Server.WriteRateLimit),
+		cfg:            cfg.TCP,
+		publicURL:      strings.TrimRight(cfg.Server.PublicURL, "/"),
+		httpPort:       cfg.Server.Port,
+		maxRequestSize: cfg.Server.MaxRequestSize,
+		conns:          make(map[net.Conn]struct{}),
+		slots:          make(chan struct{}, max(cfg.TCP.MaxConnections, 1)),
+	}
+}
+
+func (s *Server) Addr() string {
+	return ":" + s.cfg.Port
+}
+
+// ListenAndServe принимает соединения до Shutdown, после него возвращает ErrServerClosed.
+func (s *Server) ListenAndServe() error {
+	ln, err := net.Listen("tcp", s.Addr())
+	if err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	if s.closed {
+		s.mu.Unlock()
+		ln.Close()
+		return ErrServerClosed
+	}
+	s.listener = ln
+	s.mu.Unlock()
+
+	for {
+		conn, err := ln.Accept()
+		if err != nil {
+			if s.isClosed() {
+				return ErrServerClosed
+			}
+			var netErr net.Error
+			if errors.As(err, &netErr) && netErr.Timeout() {
+				continue
+			}
+			return err
+		}
+
+		// лишние соединения сразу закрываем, а не копим в очереди
+		select {
+		case s.slots <- struct{}{}:
+		default:
+			conn.Close()
+			continue
+		}
+
+		if !s.track(conn) {
+			conn.Close()
+			<-s.slots
+			return ErrServerClosed
+		}
+		go func() {
+			defer func() {
+				s.untrack(conn)
+				<-s.slots
+			}()
+			s.handle(conn)
+		}()
+	}
+}
+
+// Shutdown перестает принимать соединения и ждет начатые, пока не истечет ctx.
+// Оставшиеся после этого соединения закрываются принудительно.
+func (s *Server) Shutdown(ctx context.Context) error {
+	s.mu.Lock()
+	s.closed = true
+	if s.listener != nil {
+		s.listener.Close()
+	}
+	s.mu.Unlock()
+
+	done := make(chan struct{})
+	go func() {
+		s.wg.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		s.mu.Lock()
+		for conn := range s.conns {
+			conn.Close()
+		}
+		s.mu.Unlock()
+		return ctx.Err()
+	}
+}
+
+func (s *Server) isClosed() bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.closed
+}
+
+func (s *Server) track(conn net.Conn) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.closed {
+		return false
+	}
+	s.conns[conn] = struct{}{}
+	s.wg.Add(1)
+	return true
+}
+
+func (s *Server) untrack(conn net.Conn) {
+	conn.Close()
+	s.mu.Lock()
+	delete(s.conns, conn)
+	s.mu.Unlock()
+	s.wg.Done()
+}
+
+func (s *Server) handle(conn net.Conn) {
+	ip := remoteIP(conn)
+	ctx, span := telemetry.Start(context.Background(), tracerName, "TCPServer.handle",
+		attribute.String("client.address", ip),
+	)
+	var err error
+	defer func() { telemetry.End(span, err) }()
+
+	// паника в одном соединении не должна ронять процесс, как и в HTTP
+	defer func() {
+		if recovered := recover(); recovered != nil {
+			slog.ErrorContext(ctx, "panic recovered", slog.Any("panic", recovered), slog.String("client_ip", ip))
+			s.reply(conn, "error: "+message("internal", nil)+"\n")
+		}
+	}()
+
+	// бюджет общий с HTTP-записью того же IP
+	if s.limiter != nil && s.limit.Enabled() {
+		res, limitErr := s.limiter.Take(ctx, "write:ip:"+ip, s.limit)
+		if limitErr != nil {
+			slog.WarnContext(ctx, "rate limit check failed", slog.Any("error", limitErr))
+		} else if !res.Allowed {
+			slog.WarnContext(ctx, "rate limit exceeded", slog.String("class", "write"), slog.String("client", "ip:"+ip))
+			s.reply(conn, "error: "+message("rate_limit.exceeded", nil)+"\n")
+			return
+		}
+	}
+
+	limit := s.readLimit(ip)
+	content, err := s.readContent(conn, limit)
+	if err != nil {
+		switch {
+		case errors.Is(err, model.ErrContentTooLarge):
+			s.reply(conn, "error: "+message("content.too_large", i18n.Params{"max": limit})+"\n")
+		case errors.Is(err, model.ErrEmptyContent):
+			s.reply(conn, "error: "+message("content.empty", nil)+"\n")
+		}
+		slog.InfoContext(ctx, "tcp paste read failed", slog.String("client_ip", ip), slog.Any("error", err))
+		return
+	}
+	if !utf8.ValidString(content) || strings.IndexByte(content, 0) >= 0 {
+		s.reply(conn, "error: "+message("request.content_not_text", nil)+"\n")
+		return
+	}
+
+	paste, err := s.service.CreatePaste(ctx, service.CreatePasteRequest{
+		Content:  content,
+		ClientIP: ip,
+	})
+	if err != nil {
+		code, params := errorCode(err)
+		if code == "internal" {
+			slog.ErrorContext(ctx, "tcp paste create failed", slog.Any("error", err))
+		}
+		s.reply(conn, "error: "+message(code, params)+"\n")
+		return
+	}
+
+	var b strings.Builder
+	b.WriteString(s.baseURL(conn) + "/api/pastes/" + url.PathEscape(paste.Slug) + "/raw\n")
+	b.WriteString("edit_token: " + paste.EditToken + "\n")
+	for _, w := range paste.Warnings {
+		b.WriteString("warning: " + message(w.MessageKey, nil) + "\n")
+	}
+	s.reply(conn, b.String())
+}
+
+// readLimit - сколько читать от клиента. По TCP все анонимны, и буферизовать больше
+// их квоты незачем: 64 соединения по MaxContentSize - это гигабайты памяти.
+func (s *Server) readLimit(ip string) int64 {
+	limit := s.service.MaxPasteSize(service.QuotaSubject{ClientIP: ip})
+	if limit <= 0 {
+		limit = s.maxRequestSize
+	}
+	if limit <= 0 || limit > model.MaxContentSize {
+		limit = model.MaxContentSize
+	}
+	return limit
+}
+
+// readContent читает до EOF, паузы дольше IdleTimeout или общего ReadTimeout, но не больше limit.
+// Пауза - нормальный конец ввода: nc без -N не закрывает запись.
+func (s *Server) readContent(conn net.Conn, limit int64) (string, error) {
+	deadline := time.Now().Add(s.cfg.ReadTimeout)
+	buf := make([]byte, 32*1024)
+	var b strings.Builder
+
+	for {
+		readDeadline := time.Now().Add(s.cfg.IdleTimeout)
+		idle := readDeadline.Before(deadline)
+		if !idle {
+			readDeadline = deadline
+		}
+		if err := conn.SetReadDeadline(readDeadline); err != nil {
+			return "", err
+		}
+
+		n, err := conn.Read(buf)
+		if int64(b.Len()+n) > limit {
+			return "", model.ErrContentTooLarge
+		}
+		b.Write(buf[:n])
+
+		if err != nil {
+			var netErr net.Error
+			// истекший ReadTimeout - не конец ввода, а оборванная паста
+			if errors.Is(err, io.EOF) || (errors.As(err, &netErr) && netErr.Timeout() && idle && b.Len() > 0) {
+				break
+			}
+			return "", err
+		}
+	}
+
+	if b.Len() == 0 {
+		return "", model.ErrEmptyContent
+	}
+	return b.String(), nil
+}
+
+func (s *Server) reply(conn net.Conn, text string) {
+	_ = conn.SetWriteDeadline(time.Now().Add(writeTimeout))
+	_, _ = io.WriteString(conn, text)
+}
+
+func (s *Server) baseURL(conn net.Conn) string {
+	if s.publicURL != "" {
+		return s.publicURL
+	}
+	host, _, err := net.SplitHostPort(conn.LocalAddr().String())
+	if err != nil {
+		host = "localhost"
+	}
+	return "http://" + net.JoinHostPort(host, s.httpPort)
+}
+
+func remoteIP(conn net.Conn) string {
+	host, _, err := net.SplitHostPort(conn.RemoteAddr().String())
+	if err != nil {
+		return conn.RemoteAddr().String()
+	}
+	return host
+}
+
+// errorCode подбирает код сообщения так же, как HTTP API, но без статусов.
+func errorCode(err error) (string, i18n.Params) {
+	var (
+		quotaErr   *service.QuotaExceededError
+		violation  *policy.Violation
+		secretsErr *service.SecretsDetectedError
+		fieldErr   *model.FieldError
+	)
+
+	switch {
+	case errors.As(err, &quotaErr):
+		return "quota." + quotaErr.Quota, i18n.Params{"limit": quotaErr.Limit, "used": quotaErr.Used}
+	case errors.As(err, &violation):
+		return violation.Code, violation.Params
+	case errors.As(err, &secretsErr):
+		return "paste.secrets_detected", nil
+	case errors.As(err, &fieldErr):
+		return fieldErr.Code, fieldErr.Params
+	case errors.Is(err, service.ErrTaggerUnavailable):
+		return "tagger.unavailable", nil
+	case errors.Is(err, service.ErrSlugGeneratorUnavailable):
+		return "sluggen.unavailable", nil
+	default:
+		return "internal", nil
+	}
+}
+
+// у TCP нет Accept-Language, отвечаем на языке по умолчанию
+func message(code string, params i18n.Params) string {
+	return i18n.Message(i18n.Default, code, params)
+}
@@ -2,8 +2,9 @@ package main
 
 import (
 	"context"
+	"errors"
 	"fmt"
-	"log"
+	"log/slog"
 	"net/http"
 	"os"
 	"os/signal"
@@ -15,18 +16,26 @@ import (
 	"paste-service/internal/cache"
 	"paste-service/internal/clients/sluggen"
 	"paste-service/internal/clients/tagger"
+	"paste-service/internal/health"
+	"paste-service/internal/logging"
 	"paste-service/internal/model"
+	"paste-service/internal/policy"
+	"paste-service/internal/ratelimit"
 	"paste-service/internal/service"
+	"paste-service/internal/sharelink"
+	"paste-service/internal/tcppaste"
+	"paste-service/internal/telemetry"
 	"paste-service/repository"
 
 	"github.com/gin-gonic/gin"
 	"gorm.io/driver/postgres"
 	"gorm.io/gorm"
-	"gorm.io/gorm/logger"
+	"gorm.io/plugin/opentelemetry/tracing"
 )
 
 func main() {
 	cfg := config.LoadFromEnv()
+	logging.Setup(cfg.Log)
 
 	if cfg.Server.TestMode {
 		runTestServer(cfg)
@@ -36,11 +45,18 @@ func main() {
 }
 
 func runTestServer(cfg *config.Config) {
-	log.Println("Запуск сервера в тестовом режиме...")
-	fmt.Printf("Конфигурация: %+v\n", cfg)
+	// весь конфиг не выводим, в нем пароли
+	slog.Info("starting in test mode",
+		slog.String("port", cfg.Server.Port),
+		slog.String("cache_type", cfg.Cache.Type),
+		slog.Duration("cache_ttl", cfg.Cache.DefaultTTL),
+	)
 
 	gin.SetMode(gin.DebugMode)
 
+	shutdownTracing := setupTracing(cfg)
+	defer shutdownTracing()
+
 	cacheInstance := cache.NewInMemoryCache(cfg.Cache.RefreshTTLOnGet)
 
 	mockTagger := tagger.NewMockClient([]string{"test", "mock"}, nil)
@@ -48,30 +64,48 @@ func runTestServer(cfg *config.Config) {
 
 	mockRepo := repository.NewPasteRepository(nil, cacheInstance, cfg.Cache.DefaultTTL)
 
-	pasteService := service.NewPasteService(mockRepo, mockTagger, mockSluggen)
+	mockReports := repository.NewReportRepository(nil)
+	mockAudit := repository.NewAuditRepository(nil)
+
+	pipeline := setupPolicy(cfg)
+
+	pasteService := service.NewPasteService(mockRepo, repository.NewShareRepository(nil), mockAudit, mockTagger, mockSluggen, pipeline, setupShareSigner(cfg), cfg)
+	reportService := service.NewReportService(mockRepo, mockReports, pasteService, cfg.Moderation)
+	apiKeyService := service.NewAPIKeyService(repository.NewAPIKeyRepository(nil))
+	adminService := service.NewAdminService(mockRepo, mockAudit, mockReports, apiKeyService)
+	authService := service.NewAuthService(repository.NewUserRepository(nil), cfg.Auth)
+	idempotencyService := service.NewIdempotencyService(repository.NewIdempotencyRepository(nil), cfg.Idempotency)
+
+	// в тестовом режиме внешних зависимостей нет, readiness всегда ok
+	healthChecker := health.NewChecker(cfg.Health.Timeout)
 
-	handler := api.NewHandler(pasteService)
+	limiter := setupRateLimiter(cfg)
+	handler := api.NewHandler(pasteService, reportService, adminService, authService, apiKeyService, idempotencyService, limiter, healthChecker, cfg)
 
 	srv := &http.Server{
 		Addr:         ":" + cfg.Server.Port,
 		Handler:      handler,
 		ReadTimeout:  cfg.Server.ReadTimeout,
 		WriteTimeout: cfg.Server.WriteTimeout,
+		ErrorLog:     logging.StdLogger(slog.Default(), slog.LevelError),
 	}
 
-	startServer(srv, cfg.Server.ShutdownTimeout)
+	startServer(srv, setupTCPServer(cfg, pasteService, limiter), cfg.Server.ShutdownTimeout)
 }
 
 func runProductionServer(cfg *config.Config) {
-	setupLogger()
+	shutdownTracing := setupTracing(cfg)
+	defer shutdownTracing()
 
 	db, err := setupDatabase(cfg)
 	if err != nil {
-		log.Fatalf("Ошибка подключения к базе данных: %v", err)
+		slog.Error("database connection failed", slog.Any("error", err))
+		os.Exit(1)
 	}
 
-	if err := db.AutoMigrate(&model.Paste{}); err != nil {
-		log.Fatalf("Ошибка миграции базы данных: %v", err)
+	if err := migrateDatabase(db); err != nil {
+		slog.Error("database migration failed", slog.Any("error", err))
+		os.Exit(1)
 	}
 
 	cacheInstance := setupCache(cfg)
@@ -81,7 +115,7 @@ func runProductionServer(cfg *config.Config) {
 	taggerClient := setupTaggerClient(cfg)
 	sluggenClient, err := setupSluggenClient(cfg)
 	if err != nil {
-		log.Printf("Предупреждение: ошибка подключения к генератору slug: %v", err)
+		slog.Warn("slug generator unavailable, falling back to mock client", slog.Any("error", err))
 		sluggenClient = sluggen.NewMockClient("generated-slug", nil)
 	}
 	defer func() {
@@ -90,49 +124,121 @@ func runProductionServer(cfg *config.Config) {
 		}
 	}()
 
-	pasteService := service.NewPasteService(repo, taggerClient, sluggenClient)
+	reportRepo := repository.NewReportRepository(db)
+	auditRepo := repository.NewAuditRepository(db)
+
+	pipeline := setupPolicy(cfg)
+
+	if cfg.Security.TokenPepper == "" {
+		slog.Warn("SECURITY_TOKENPEPPER is not set, edit token hashes are not peppered")
+	}
+
+	shareRepo := repository.NewShareRepository(db)
+	signer := setupShareSigner(cfg)
+	if !signer.Enabled() {
+		slog.Warn("SHARE_SIGNINGKEYS is not set, share links are disabled")
+	}
 
-	handler := api.NewHandler(pasteService)
+	pasteService := service.NewPasteService(repo, shareRepo, auditRepo, taggerClient, sluggenClient, pipeline, signer, cfg)
+	reportService := service.NewReportService(repo, reportRepo, pasteService, cfg.Moderation)
+	apiKeyService := service.NewAPIKeyService(repository.NewAPIKeyRepository(db))
+	adminService := service.NewAdminService(repo, auditRepo, reportRepo, apiKeyService)
+	authService := service.NewAuthService(repository.NewUserRepository(db), cfg.Auth)
+	idempotencyService := service.NewIdempotencyService(repository.NewIdempotencyRepository(db), cfg.Idempotency)
+
+	janitorCtx, stopJanitor := context.WithCancel(context.Background())
+	defer stopJanitor()
+	go idempotencyService.RunJanitor(janitorCtx)
+
+	healthChecker := setupHealthChecker(cfg, db, cacheInstance, taggerClient, sluggenClient)
+
+	limiter := setupRateLimiter(cfg)
+	// закрываем после остановки серверов: начатые запросы еще проверяют лимит
+	defer func() {
+		if store, ok := limiter.(*ratelimit.RedisStore); ok {
+			if err := store.Close(); err != nil {
+				slog.Error("redis rate limit store close failed", slog.Any("error", err))
+			}
+		}
+	}()
+	handler := api.NewHandler(pasteService, reportService, adminService, authService, apiKeyService, idempotencyService, limiter, healthChecker, cfg)
 
 	srv := &http.Server{
 		Addr:         ":" + cfg.Server.Port,
 		Handler:      handler,
 		ReadTimeout:  cfg.Server.ReadTimeout,
 		WriteTimeout: cfg.Server.WriteTimeout,
+		ErrorLog:     logging.StdLogger(slog.Default(), slog.LevelError),
 	}
 
-	startServer(srv, cfg.Server.ShutdownTimeout)
+	startServer(srv, setupTCPServer(cfg, pasteService, limiter), cfg.Server.ShutdownTimeout)
 }
 
-func startServer(srv *http.Server, shutdownTimeout time.Duration) {
+// startServer запускает HTTP и, если он включен, TCP-прием и останавливает их вместе по сигналу.
+func startServer(srv *http.Server, tcp *tcppaste.Server, shutdownTimeout time.Duration) {
 	go func() {
-		log.Printf("Сервер запущен на порту %s", srv.Addr[1:])
+		slog.Info("server started", slog.String("port", srv.Addr[1:]))
 		if err := srv.ListenAndServe(); err != nil && err != http.ErrServerClosed {
-			log.Fatalf("Ошибка запуска сервера: %v", err)
+			slog.Error("server failed", slog.Any("error", err))
+			os.Exit(1)
 		}
 	}()
 
+	if tcp != nil {
+		go func() {
+			slog.Info("tcp paste listener started", slog.String("port", tcp.Addr()[1:]))
+			if err := tcp.ListenAndServe(); err != nil && !errors.Is(err, tcppaste.ErrServerClosed) {
+				slog.Error("tcp paste listener failed", slog.Any("error", err))
+				os.Exit(1)
+			}
+		}()
+	}
+
 	quit := make(chan os.Signal, 1)
 	signal.Notify(quit, syscall.SIGINT, syscall.SIGTERM)
 	<-quit
-	log.Println("Получен сигнал остановки, завершение работы...")
+	slog.Info("shutdown signal received")
 
 	cache.CloseRedisConnections()
-	log.Println("Соединения с Redis закрыты")
+	slog.Info("redis connections closed")
 
 	ctx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
 	defer cancel()
 
+	// TCP останавливаем параллельно с HTTP, чтобы оба уложились в один таймаут
+	tcpDone := make(chan error, 1)
+	if tcp != nil {
+		go func() { tcpDone <- tcp.Shutdown(ctx) }()
+	} else {
+		tcpDone <- nil
+	}
+
 	if err := srv.Shutdown(ctx); err != nil {
-		log.Fatalf("Ошибка при остановке сервера: %v", err)
+		slog.Error("server shutdown failed", slog.Any("error", err))
+		os.Exit(1)
+	}
+	if err := <-tcpDone; err != nil {
+		slog.Error("tcp paste listener shutdown failed", slog.Any("error", err))
+		os.Exit(1)
 	}
 
-	log.Println("Сервер остановлен")
+	slog.Info("server stopped")
 }
 
-func setupLogger() {
-	log.SetFlags(log.LstdFlags | log.Lshortfile)
-	log.Println("Логгер настроен")
+func setupTracing(cfg *config.Config) func() {
+	shutdown, err := telemetry.Setup(context.Background(), cfg.Tracing)
+	if err != nil {
+		slog.Error("tracing setup failed, span export disabled", slog.Any("error", err))
+		return func() {}
+	}
+
+	return func() {
+		ctx, cancel := context.WithTimeout(context.Background(), cfg.Server.ShutdownTimeout)
+		defer cancel()
+		if err := shutdown(ctx); err != nil {
+			slog.Error("tracing shutdown failed", slog.Any("error", err))
+		}
+	}
 }
 
 func setupDatabase(cfg *config.Config) (*gorm.DB, error) {
@@ -146,42 +252,101 @@ func setupDatabase(cfg *config.Config) (*gorm.DB, error) {
 		cfg.Database.SSLMode,
 	)
 
-	gormLogger := logger.New(
-		log.New(os.Stdout, "\r\n", log.LstdFlags),
-		logger.Config{
-			SlowThreshold:             time.Second,
-			LogLevel:                  logger.Info,
-			IgnoreRecordNotFoundError: true,
-			Colorful:                  true,
-		},
-	)
+	gormLogger := logging.NewGormLogger(slog.Default(), logging.ParseLevel(cfg.Log.Level), time.Second)
 
 	db, err := gorm.Open(postgres.Open(dsn), &gorm.Config{
 		Logger: gormLogger,
+		// нарушения уникальности приходят как gorm.ErrDuplicatedKey
+		TranslateError: true,
 	})
 	if err != nil {
 		return nil, err
 	}
 
-	log.Println("Подключение к базе данных установлено")
+	// значения параметров не пишем в спаны, там может быть содержимое паст
+	if err := db.Use(tracing.NewPlugin(
+		tracing.WithDBName(cfg.Database.DBName),
+		tracing.WithoutQueryVariables(),
+		tracing.WithoutMetrics(),
+	)); err != nil {
+		return nil, err
+	}
+
+	slog.Info("database connected", slog.String("host", cfg.Database.Host), slog.String("dbname", cfg.Database.DBName))
 	return db, nil
 }
 
+func migrateDatabase(db *gorm.DB) error {
+	if err := db.AutoMigrate(&model.Paste{}, &model.AuditEntry{}, &model.Report{}, &model.ShareLink{}, &model.User{}, &model.Session{}, &model.APIKey{}, &model.IdempotencyRecord{}); err != nil {
+		return err
+	}
+
+	// прежний индекс держал уникальность жалобы по всем статусам, и после решения
+	// по жалобе клиент уже не мог пожаловаться снова. Его сменил частичный по открытым
+	if db.Migrator().HasIndex(&model.Report{}, "idx_report_paste_reporter") {
+		if err := db.Migrator().DropIndex(&model.Report{}, "idx_report_paste_reporter"); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
 func setupCache(cfg *config.Config) cache.Cache {
 	if cfg.Cache.Type == "redis" {
 		redisCache, err := cache.NewRedisCache(cfg.Cache.RedisURL)
 		if err != nil {
-			log.Printf("Ошибка подключения к Redis: %v, используется in-memory кэш", err)
+			slog.Error("redis connection failed, falling back to in-memory cache", slog.Any("error", err))
 			return cache.NewInMemoryCache(cfg.Cache.RefreshTTLOnGet)
 		}
-		log.Println("Используется Redis кэш")
+		slog.Info("using redis cache")
 		return redisCache
 	}
 
-	log.Println("Используется in-memory кэш")
+	slog.Info("using in-memory cache")
 	return cache.NewInMemoryCache(cfg.Cache.RefreshTTLOnGet)
 }
 
+func setupRateLimiter(cfg *config.Config) ratelimit.Store {
+	if cfg.Server.RateLimitStore == "redis" && !cfg.Server.TestMode {
+		store, err := ratelimit.NewRedisStore(cfg.Cache.RedisURL)
+		if err != nil {
+			slog.Error("redis rate limit store unavailable, falling back to in-memory", slog.Any("error", err))
+			return ratelimit.NewMemoryStore()
+		}
+		slog.Info("using redis rate limit store")
+		return store
+	}
+	return ratelimit.NewMemoryStore()
+}
+
+func setupTCPServer(cfg *config.Config, pasteService *service.PasteService, limiter ratelimit.Store) *tcppaste.Server {
+	if !cfg.TCP.Enabled {
+		return nil
+	}
+	if cfg.Server.PublicURL == "" {
+		slog.Warn("SERVER_PUBLICURL is not set, tcp paste links use the listener address")
+	}
+	return tcppaste.NewServer(pasteService, limiter, cfg)
+}
+
+func setupPolicy(cfg *config.Config) *policy.Pipeline {
+	pipeline, err := policy.NewFromConfig(cfg.Policy)
+	if err != nil {
+		slog.Error("content policy setup failed", slog.Any("error", err))
+		os.Exit(1)
+	}
+	return pipeline
+}
+
+func setupShareSigner(cfg *config.Config) *sharelink.Signer {
+	signer, err := sharelink.NewSigner(cfg.Share.SigningKeys)
+	if err != nil {
+		slog.Error("share link signer setup failed", slog.Any("error", err))
+		os.Exit(1)
+	}
+	return signer
+}
+
 func setupTaggerClient(cfg *config.Config) tagger.TaggerClient {
 	taggerConfig := tagger.Config{
 		BaseURL:     cfg.Tagger.BaseURL,
@@ -206,3 +371,63 @@ func setupSluggenClient(cfg *config.Config) (sluggen.SlugClient, error) {
 
 	return client, nil
 }
+
+type pinger interface {
+	Ping(ctx context.Context) error
+}
+
+func setupHealthChecker(
+	cfg *config.Config,
+	db *gorm.DB,
+	cacheInstance cache.Cache,
+	taggerClient tagger.TaggerClient,
+	sluggenClient sluggen.SlugClient,
+) *health.Checker {
+	checker := health.NewChecker(cfg.Health.Timeout)
+
+	checker.Register(health.Dependency{
+		Name:     "database",
+		Critical: cfg.Health.IsCritical("database"),
+		Check: func(ctx context.Context) error {
+			sqlDB, err := db.DB()
+			if err != nil {
+				return err
+			}
+			return sqlDB.PingContext(ctx)
+		},
+	})
+
+	if cfg.Cache.Type == "redis" {
+		checker.Register(health.Dependency{
+			Name:     "redis",
+			Critical: cfg.Health.IsCritical("redis"),
+			Check:    pingOrFallback(cacheInstance, "redis недоступен при старте, используется in-memory кэш"),
+		})
+	}
+
+	checker.Register(health.Dependency{
+		Name:     "tagger",
+		Critical: cfg.Health.IsCritical("tagger"),
+		Check:    pingOrFallback(taggerClient, "используется мок-клиент тэггера"),
+	})
+
+	checker.Register(health.Dependency{
+		Name:     "sluggen",
+		Critical: cfg.Health.IsCritical("sluggen"),
+		Check:    pingOrFallback(sluggenClient, "генератор slug недоступен при старте, используется мок-клиент"),
+	})
+
+	return checker
+}
+
+// pingOrFallback проверяет зависимость через Ping, а если вместо настоящего
+// клиента подставлен запасной вариант, всегда сообщает о недоступности.
+func pingOrFallback(dep interface{}, fallbackMsg string) health.CheckFunc {
+	p, ok := dep.(pinger)
+	if !ok {
+		return func(context.Context) error {
+			return errors.New(fallbackMsg)
+		}
+	}
+	return p.Ping
+}
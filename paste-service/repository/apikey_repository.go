@@ -0,0 +1,95 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"paste-service/internal/model"
+	"paste-service/internal/telemetry"
+
+	"gorm.io/gorm"
+)
+
+var ErrAPIKeyNotFound = errors.New("ключ не найден")
+
+type APIKeyRepository struct {
+	DB *gorm.DB
+}
+
+func NewAPIKeyRepository(db *gorm.DB) *APIKeyRepository {
+	return &APIKeyRepository{DB: db}
+}
+
+func (r *APIKeyRepository) Create(ctx context.Context, key *model.APIKey) (err error) {
+	ctx, span := telemetry.Start(ctx, tracerName, "APIKeyRepository.Create")
+	defer func() { telemetry.End(span, err) }()
+
+	if err := key.Validate(); err != nil {
+		return err
+	}
+	return r.DB.WithContext(ctx).Create(key).Error
+}
+
+func (r *APIKeyRepository) GetByHash(ctx context.Context, hash string) (_ *model.APIKey, err error) {
+	ctx, span := telemetry.Start(ctx, tracerName, "APIKeyRepository.GetByHash")
+	defer func() { telemetry.End(span, err) }()
+
+	var key model.APIKey
+	if err := r.DB.WithContext(ctx).Where("key_hash = ?", hash).First(&key).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrAPIKeyNotFound
+		}
+		return nil, err
+	}
+	return &key, nil
+}
+
+// List отдает ключи пользователя, а при пустом userID - все ключи.
+func (r *APIKeyRepository) List(ctx context.Context, userID string, limit, offset int) (_ []model.APIKey, err error) {
+	ctx, span := telemetry.Start(ctx, tracerName, "APIKeyRepository.List")
+	defer func() { telemetry.End(span, err) }()
+
+	query := r.DB.WithContext(ctx).Model(&model.APIKey{})
+	if userID != "" {
+		query = query.Where("user_id = ?", userID)
+	}
+
+	var keys []model.APIKey
+	if err := query.Order("created_at DESC").Limit(limit).Offset(offset).Find(&keys).Error; err != nil {
+		return nil, err
+	}
+	return keys, nil
+}
+
+// Revoke отзывает ключ. При непустом userID отозвать можно только свой ключ.
+func (r *APIKeyRepository) Revoke(ctx context.Context, id, userID string) (err error) {
+	ctx, span := telemetry.Start(ctx, tracerName, "APIKeyRepository.Revoke")
+	defer func() { telemetry.End(span, err) }()
+
+	query := r.DB.WithContext(ctx).Model(&model.APIKey{}).Where("id = ? AND revoked = ?", id, false)
+	if userID != "" {
+		query = query.Where("user_id = ?", userID)
+	}
+
+	result := query.Updates(map[string]interface{}{"revoked": true, "revoked_at": time.Now()})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return ErrAPIKeyNotFound
+	}
+	return nil
+}
+
+// TouchLastUsed обновляет время использования не чаще раза в interval,
+// чтобы не писать в БД на каждый запрос.
+func (r *APIKeyRepository) TouchLastUsed(ctx context.Context, id string, interval time.Duration) (err error) {
+	ctx, span := telemetry.Start(ctx, tracerName, "APIKeyRepository.TouchLastUsed")
+	defer func() { telemetry.End(span, err) }()
+
+	now := time.Now()
+	return r.DB.WithContext(ctx).Model(&model.APIKey{}).
+		Where("id = ? AND (last_used_at IS NULL OR last_used_at < ?)", id, now.Add(-interval)).
+		Update("last_used_at", now).Error
+}
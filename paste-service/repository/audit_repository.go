@@ -0,0 +1,47 @@
+package repository
+
+import (
+	"context"
+
+	"paste-service/internal/model"
+	"paste-service/internal/telemetry"
+
+	"go.opentelemetry.io/otel/attribute"
+	"gorm.io/gorm"
+)
+
+type AuditRepository struct {
+	DB *gorm.DB
+}
+
+func NewAuditRepository(db *gorm.DB) *AuditRepository {
+	return &AuditRepository{DB: db}
+}
+
+func (r *AuditRepository) Create(ctx context.Context, entry *model.AuditEntry) (err error) {
+	ctx, span := telemetry.Start(ctx, tracerName, "AuditRepository.Create",
+		attribute.String("audit.action", entry.Action),
+	)
+	defer func() { telemetry.End(span, err) }()
+
+	return r.DB.WithContext(ctx).Create(entry).Error
+}
+
+func (r *AuditRepository) List(ctx context.Context, actor, action string, limit, offset int) (_ []model.AuditEntry, err error) {
+	ctx, span := telemetry.Start(ctx, tracerName, "AuditRepository.List")
+	defer func() { telemetry.End(span, err) }()
+
+	query := r.DB.WithContext(ctx).Model(&model.AuditEntry{})
+	if actor != "" {
+		query = query.Where("actor = ?", actor)
+	}
+	if action != "" {
+		query = query.Where("action = ?", action)
+	}
+
+	var entries []model.AuditEntry
+	if err := query.Order("created_at DESC").Limit(limit).Offset(offset).Find(&entries).Error; err != nil {
+		return nil, err
+	}
+	return entries, nil
+}
@@ -0,0 +1,83 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"paste-service/internal/model"
+	"paste-service/internal/telemetry"
+
+	"gorm.io/gorm"
+)
+
+var (
+	ErrIdempotencyRecordExists   = errors.New("запись идемпотентности уже существует")
+	ErrIdempotencyRecordNotFound = errors.New("запись идемпотентности не найдена")
+)
+
+type IdempotencyRepository struct {
+	DB *gorm.DB
+}
+
+func NewIdempotencyRepository(db *gorm.DB) *IdempotencyRepository {
+	return &IdempotencyRepository{DB: db}
+}
+
+// Create занимает ключ. Если запись уже есть, возвращает ErrIdempotencyRecordExists.
+func (r *IdempotencyRepository) Create(ctx context.Context, record *model.IdempotencyRecord) (err error) {
+	ctx, span := telemetry.Start(ctx, tracerName, "IdempotencyRepository.Create")
+	defer func() { telemetry.End(span, err) }()
+
+	if err := r.DB.WithContext(ctx).Create(record).Error; err != nil {
+		if errors.Is(err, gorm.ErrDuplicatedKey) {
+			return ErrIdempotencyRecordExists
+		}
+		return err
+	}
+	return nil
+}
+
+func (r *IdempotencyRepository) Get(ctx context.Context, id string) (_ *model.IdempotencyRecord, err error) {
+	ctx, span := telemetry.Start(ctx, tracerName, "IdempotencyRepository.Get")
+	defer func() { telemetry.End(span, err) }()
+
+	var record model.IdempotencyRecord
+	if err := r.DB.WithContext(ctx).Where("id = ?", id).First(&record).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrIdempotencyRecordNotFound
+		}
+		return nil, err
+	}
+	return &record, nil
+}
+
+// Complete сохраняет ответ в занятую запись.
+func (r *IdempotencyRepository) Complete(ctx context.Context, id string, status int, contentType, headers string, response []byte) (err error) {
+	ctx, span := telemetry.Start(ctx, tracerName, "IdempotencyRepository.Complete")
+	defer func() { telemetry.End(span, err) }()
+
+	return r.DB.WithContext(ctx).Model(&model.IdempotencyRecord{}).
+		Where("id = ? AND status_code = 0", id).
+		Updates(map[string]interface{}{
+			"status_code":  status,
+			"content_type": contentType,
+			"headers":      headers,
+			"response":     response,
+		}).Error
+}
+
+func (r *IdempotencyRepository) Delete(ctx context.Context, id string) (err error) {
+	ctx, span := telemetry.Start(ctx, tracerName, "IdempotencyRepository.Delete")
+	defer func() { telemetry.End(span, err) }()
+
+	return r.DB.WithContext(ctx).Where("id = ?", id).Delete(&model.IdempotencyRecord{}).Error
+}
+
+func (r *IdempotencyRepository) DeleteExpired(ctx context.Context) (_ int64, err error) {
+	ctx, span := telemetry.Start(ctx, tracerName, "IdempotencyRepository.DeleteExpired")
+	defer func() { telemetry.End(span, err) }()
+
+	result := r.DB.WithContext(ctx).Where("expires_at <= ?", time.Now()).Delete(&model.IdempotencyRecord{})
+	return result.RowsAffected, result.Error
+}
@@ -0,0 +1,229 @@
+package repository
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"time"
+
+	"paste-service/internal/model"
+	"paste-service/internal/telemetry"
+
+	"go.opentelemetry.io/otel/attribute"
+	"gorm.io/gorm"
+)
+
+// PasteFilter - фильтр для админского списка паст. Пустые поля не ограничивают выборку.
+type PasteFilter struct {
+	SlugPrefix    string
+	Tag           string
+	Expired       *bool
+	Locked        *bool
+	Hidden        *bool
+	Visibility    string
+	CreatedAfter  *time.Time
+	CreatedBefore *time.Time
+	Limit         int
+	Offset        int
+}
+
+type PasteStats struct {
+	Total        int64 `json:"total"`
+	Active       int64 `json:"active"`
+	Expired      int64 `json:"expired"`
+	Locked       int64 `json:"locked"`
+	Hidden       int64 `json:"hidden"`
+	Private      int64 `json:"private"`
+	TotalViews   int64 `json:"total_views"`
+	ContentBytes int64 `json:"content_bytes"`
+	CreatedLast  int64 `json:"created_last_24h"`
+}
+
+// ListPastes отдает пасты без учета срока действия, в том числе истекшие.
+func (r *PasteRepository) ListPastes(ctx context.Context, filter PasteFilter) (_ []model.Paste, total int64, err error) {
+	ctx, span := telemetry.Start(ctx, tracerName, "PasteRepository.ListPastes",
+		attribute.Int("query.limit", filter.Limit),
+		attribute.Int("query.offset", filter.Offset),
+	)
+	defer func() { telemetry.End(span, err) }()
+
+	query := r.DB.WithContext(ctx).Model(&model.Paste{})
+	now := time.Now()
+
+	if filter.SlugPrefix != "" {
+		query = query.Where("slug LIKE ?", escapeLike(filter.SlugPrefix)+"%")
+	}
+	if filter.Tag != "" {
+		tagJSON, err := json.Marshal([]string{filter.Tag})
+		if err != nil {
+			return nil, 0, err
+		}
+		query = query.Where("tags @> ?::jsonb", string(tagJSON))
+	}
+	if filter.Expired != nil {
+		if *filter.Expired {
+			query = query.Where("expires IS NOT NULL AND expires <= ?", now)
+		} else {
+			query = query.Where("expires IS NULL OR expires > ?", now)
+		}
+	}
+	if filter.Locked != nil {
+		query = query.Where("locked = ?", *filter.Locked)
+	}
+	if filter.Hidden != nil {
+		query = query.Where("hidden = ?", *filter.Hidden)
+	}
+	if filter.Visibility != "" {
+		query = query.Where("visibility = ?", filter.Visibility)
+	}
+	if filter.CreatedAfter != nil {
+		query = query.Where("created_at >= ?", *filter.CreatedAfter)
+	}
+	if filter.CreatedBefore != nil {
+		query = query.Where("created_at < ?", *filter.CreatedBefore)
+	}
+
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	var pastes []model.Paste
+	if err := query.Order("created_at DESC").
+		Limit(filter.Limit).
+		Offset(filter.Offset).
+		Find(&pastes).Error; err != nil {
+		return nil, 0, err
+	}
+
+	return pastes, total, nil
+}
+
+// GetPasteBySlugAny читает пасту напрямую из БД, не проверяя срок действия.
+func (r *PasteRepository) GetPasteBySlugAny(ctx context.Context, slug string) (_ *model.Paste, err error) {
+	ctx, span := telemetry.Start(ctx, tracerName, "PasteRepository.GetPasteBySlugAny",
+		attribute.String("paste.slug", slug),
+	)
+	defer func() { telemetry.End(span, err) }()
+
+	var paste model.Paste
+	if err := r.DB.WithContext(ctx).Where("slug = ?", slug).First(&paste).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrPasteNotFound
+		}
+		return nil, err
+	}
+	return &paste, nil
+}
+
+func (r *PasteRepository) DeletePaste(ctx context.Context, slug string) (err error) {
+	ctx, span := telemetry.Start(ctx, tracerName, "PasteRepository.DeletePaste",
+		attribute.String("paste.slug", slug),
+	)
+	defer func() { telemetry.End(span, err) }()
+
+	result := r.DB.WithContext(ctx).Where("slug = ?", slug).Delete(&model.Paste{})
+	if result.Error != nil {
+		return result.Error
+	}
+	r.Cache.Invalidate(ctx, slug)
+
+	if result.RowsAffected == 0 {
+		return ErrPasteNotFound
+	}
+	return nil
+}
+
+// SetExpires меняет срок действия, nil снимает ограничение.
+func (r *PasteRepository) SetExpires(ctx context.Context, slug string, expires *time.Time) error {
+	return r.updateColumns(ctx, "PasteRepository.SetExpires", slug, nil, map[string]interface{}{
+		"expires": expires,
+	})
+}
+
+// SetExpiresAtVersion меняет срок, только если паста все еще в версии version.
+// Иначе возвращает ErrVersionConflict.
+func (r *PasteRepository) SetExpiresAtVersion(ctx context.Context, slug string, version int64, expires *time.Time) error {
+	return r.updateColumns(ctx, "PasteRepository.SetExpiresAtVersion", slug, &version, map[string]interface{}{
+		"expires": expires,
+	})
+}
+
+func (r *PasteRepository) SetLocked(ctx context.Context, slug string, locked bool) error {
+	return r.updateColumns(ctx, "PasteRepository.SetLocked", slug, nil, map[string]interface{}{
+		"locked": locked,
+	})
+}
+
+// SetHidden скрывает пасту из листингов или возвращает ее туда.
+func (r *PasteRepository) SetHidden(ctx context.Context, slug string, hidden bool) error {
+	return r.updateColumns(ctx, "PasteRepository.SetHidden", slug, nil, map[string]interface{}{
+		"hidden": hidden,
+	})
+}
+
+// updateColumns обновляет колонки и поднимает версию. С version обновление условное:
+// если пасту успели изменить, ничего не пишется.
+func (r *PasteRepository) updateColumns(ctx context.Context, spanName, slug string, version *int64, columns map[string]interface{}) (err error) {
+	ctx, span := telemetry.Start(ctx, tracerName, spanName,
+		attribute.String("paste.slug", slug),
+	)
+	defer func() { telemetry.End(span, err) }()
+
+	columns["updated_at"] = time.Now()
+	columns["version"] = gorm.Expr("version + 1")
+	query := r.DB.WithContext(ctx).Model(&model.Paste{}).Where("slug = ?", slug)
+	if version != nil {
+		query = query.Where("version = ?", *version)
+	}
+	result := query.Updates(columns)
+	if result.Error != nil {
+		return result.Error
+	}
+	// в кэше могла остаться старая версия, пусть следующий запрос перечитает из БД
+	r.Cache.Invalidate(ctx, slug)
+
+	if result.RowsAffected == 0 {
+		if version != nil {
+			return ErrVersionConflict
+		}
+		return ErrPasteNotFound
+	}
+	return nil
+}
+
+func (r *PasteRepository) GetStats(ctx context.Context) (_ *PasteStats, err error) {
+	ctx, span := telemetry.Start(ctx, tracerName, "PasteRepository.GetStats")
+	defer func() { telemetry.End(span, err) }()
+
+	now := time.Now()
+	var stats PasteStats
+	if err := r.DB.WithContext(ctx).Raw(`
+		SELECT
+			COUNT(*) AS total,
+			COUNT(*) FILTER (WHERE expires IS NULL OR expires > @now) AS active,
+			COUNT(*) FILTER (WHERE expires IS NOT NULL AND expires <= @now) AS expired,
+			COUNT(*) FILTER (WHERE locked) AS locked,
+			COUNT(*) FILTER (WHERE hidden) AS hidden,
+			COUNT(*) FILTER (WHERE visibility = 'private') AS private,
+			COALESCE(SUM(view_count), 0) AS total_views,
+			COALESCE(SUM(octet_length(content)), 0) AS content_bytes,
+			COUNT(*) FILTER (WHERE created_at > @since) AS created_last
+		FROM pastes`,
+		map[string]interface{}{"now": now, "since": now.Add(-24 * time.Hour)},
+	).Scan(&stats).Error; err != nil {
+		return nil, err
+	}
+
+	return &stats, nil
+}
+
+func escapeLike(s string) string {
+	out := make([]rune, 0, len(s))
+	for _, r := range s {
+		if r == '%' || r == '_' || r == '\\' {
+			out = append(out, '\\')
+		}
+		out = append(out, r)
+	}
+	return string(out)
+}
@@ -0,0 +1,53 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"paste-service/internal/model"
+	"paste-service/internal/telemetry"
+
+	"go.opentelemetry.io/otel/attribute"
+)
+
+// ListByOwner отдает все пасты пользователя, включая приватные, скрытые и истекшие.
+func (r *PasteRepository) ListByOwner(ctx context.Context, ownerID string, limit, offset int) (_ []model.Paste, total int64, err error) {
+	ctx, span := telemetry.Start(ctx, tracerName, "PasteRepository.ListByOwner",
+		attribute.Int("query.limit", limit),
+		attribute.Int("query.offset", offset),
+	)
+	defer func() { telemetry.End(span, err) }()
+
+	query := r.DB.WithContext(ctx).Model(&model.Paste{}).Where("owner_id = ?", ownerID)
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	var pastes []model.Paste
+	if err := query.Order("created_at DESC").Limit(limit).Offset(offset).Find(&pastes).Error; err != nil {
+		return nil, 0, err
+	}
+	return pastes, total, nil
+}
+
+// SetOwner привязывает анонимную пасту к пользователю. Пасту с владельцем
+// перепривязать нельзя, повторная привязка к тому же пользователю не ошибка.
+func (r *PasteRepository) SetOwner(ctx context.Context, slug, ownerID string) (err error) {
+	ctx, span := telemetry.Start(ctx, tracerName, "PasteRepository.SetOwner",
+		attribute.String("paste.slug", slug),
+	)
+	defer func() { telemetry.End(span, err) }()
+
+	result := r.DB.WithContext(ctx).Model(&model.Paste{}).
+		Where("slug = ? AND (owner_id IS NULL OR owner_id = ?)", slug, ownerID).
+		Updates(map[string]interface{}{"owner_id": ownerID, "updated_at": time.Now()})
+	if result.Error != nil {
+		return result.Error
+	}
+	r.Cache.Invalidate(ctx, slug)
+
+	if result.RowsAffected == 0 {
+		return ErrPasteAlreadyOwned
+	}
+	return nil
+}
@@ -1,18 +1,26 @@
 package repository
 
 import (
+	"context"
 	"errors"
 	"time"
 
 	"paste-service/internal/cache"
 	"paste-service/internal/model"
+	"paste-service/internal/telemetry"
 
+	"go.opentelemetry.io/otel/attribute"
 	"gorm.io/gorm"
 )
 
+const tracerName = "paste-service/repository"
+
 var (
-	ErrPasteNotFound = errors.New("паста не найдена")
-	ErrPasteExpired  = errors.New("срок действия пасты истек")
+	ErrPasteNotFound     = errors.New("паста не найдена")
+	ErrPasteExpired      = errors.New("срок действия пасты истек")
+	ErrEditTokenChanged  = errors.New("токен редактирования был изменен")
+	ErrPasteAlreadyOwned = errors.New("у пасты уже есть владелец")
+	ErrVersionConflict   = errors.New("версия пасты изменилась")
 )
 
 type PasteRepository struct {
@@ -29,35 +37,52 @@ func NewPasteRepository(db *gorm.DB, cache cache.Cache, cacheTTL time.Duration)
 	}
 }
 
-func (r *PasteRepository) CreatePaste(p *model.Paste) error {
+// CreatePaste сохраняет новую пасту. С check квота проверяется заново при вставке.
+func (r *PasteRepository) CreatePaste(ctx context.Context, p *model.Paste, check QuotaCheck) (err error) {
+	ctx, span := telemetry.Start(ctx, tracerName, "PasteRepository.CreatePaste",
+		attribute.String("paste.slug", p.Slug),
+	)
+	defer func() { telemetry.End(span, err) }()
+
 	if err := p.Validate(); err != nil {
 		return err
 	}
 
-	err := r.DB.Create(p).Error
+	p.Version = 1
+	if check != nil && p.Creator != "" {
+		err = createWithinQuota(r.DB.WithContext(ctx), p, check)
+	} else {
+		err = r.DB.WithContext(ctx).Create(p).Error
+	}
 	if err != nil {
 		return err
 	}
-	r.Cache.Set(p.Slug, p, r.cacheTTL)
+	r.Cache.Set(ctx, p.Slug, p, r.cacheTTL)
+	r.invalidateQuotaUsage(ctx, p.Creator)
 	return nil
 }
 
-func (r *PasteRepository) GetPasteBySlug(slug string) (*model.Paste, error) {
+func (r *PasteRepository) GetPasteBySlug(ctx context.Context, slug string) (_ *model.Paste, err error) {
+	ctx, span := telemetry.Start(ctx, tracerName, "PasteRepository.GetPasteBySlug",
+		attribute.String("paste.slug", slug),
+	)
+	defer func() { telemetry.End(span, err) }()
+
 	// типизированное
 	var cachedPaste model.Paste
-	if r.Cache.GetTyped(slug, &cachedPaste) {
+	if r.Cache.GetTyped(ctx, slug, &cachedPaste) {
 		if cachedPaste.HasExpired() {
-			r.Cache.Invalidate(slug)
+			r.Cache.Invalidate(ctx, slug)
 			return nil, ErrPasteExpired
 		}
 		return &cachedPaste, nil
 	}
 
 	// стандарт
-	if cached, ok := r.Cache.Get(slug); ok {
+	if cached, ok := r.Cache.Get(ctx, slug); ok {
 		if paste, valid := cached.(*model.Paste); valid {
 			if paste.HasExpired() {
-				r.Cache.Invalidate(slug)
+				r.Cache.Invalidate(ctx, slug)
 				return nil, ErrPasteExpired
 			}
 			return paste, nil
@@ -65,7 +90,7 @@ func (r *PasteRepository) GetPasteBySlug(slug string) (*model.Paste, error) {
 	}
 
 	var paste model.Paste
-	if err := r.DB.Where("slug = ?", slug).First(&paste).Error; err != nil {
+	if err := r.DB.WithContext(ctx).Where("slug = ?", slug).First(&paste).Error; err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
 			return nil, ErrPasteNotFound
 		}
@@ -74,17 +99,22 @@ func (r *PasteRepository) GetPasteBySlug(slug string) (*model.Paste, error) {
 	if paste.HasExpired() {
 		return nil, ErrPasteExpired
 	}
-	r.Cache.Set(slug, &paste, r.cacheTTL)
+	r.Cache.Set(ctx, slug, &paste, r.cacheTTL)
 	return &paste, nil
 }
 
-func (r *PasteRepository) UpdatePaste(p *model.Paste) error {
+func (r *PasteRepository) UpdatePaste(ctx context.Context, p *model.Paste) (err error) {
+	ctx, span := telemetry.Start(ctx, tracerName, "PasteRepository.UpdatePaste",
+		attribute.String("paste.slug", p.Slug),
+	)
+	defer func() { telemetry.End(span, err) }()
+
 	if err := p.Validate(); err != nil {
 		return err
 	}
 
 	var exists model.Paste
-	if err := r.DB.Where("slug = ?", p.Slug).First(&exists).Error; err != nil {
+	if err := r.DB.WithContext(ctx).Where("slug = ?", p.Slug).First(&exists).Error; err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
 			return ErrPasteNotFound
 		}
@@ -94,25 +124,79 @@ func (r *PasteRepository) UpdatePaste(p *model.Paste) error {
 	if exists.HasExpired() {
 		return ErrPasteExpired
 	}
+	if exists.Version != p.Version {
+		r.Cache.Invalidate(ctx, p.Slug)
+		return ErrVersionConflict
+	}
 
-	p.UpdatedAt = time.Now()
-	if err := r.DB.Save(p).Error; err != nil {
-		return err
+	// пишем только редактируемые поля и только если версия не изменилась с момента
+	// чтения: так правка по устаревшей копии не перетрет чужую. Просмотры, токен и
+	// владелец меняются своими методами
+	now := time.Now()
+	result := r.DB.WithContext(ctx).Model(&model.Paste{}).
+		Where("id = ? AND version = ?", p.ID, p.Version).
+		Updates(map[string]interface{}{
+			"content":           p.Content,
+			"tags":              p.Tags,
+			"expires":           p.Expires,
+			"visibility":        p.Visibility,
+			"title":             p.Title,
+			"description":       p.Description,
+			"filename":          p.Filename,
+			"language":          p.Language,
+			"title_inferred":    p.TitleInferred,
+			"language_inferred": p.LanguageInferred,
+			"updated_at":        now,
+			"version":           gorm.Expr("version + 1"),
+		})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		r.Cache.Invalidate(ctx, p.Slug)
+		return ErrVersionConflict
 	}
 
-	r.Cache.Set(p.Slug, p, r.cacheTTL)
+	exists.Content = p.Content
+	exists.Tags = p.Tags
+	exists.Expires = p.Expires
+	exists.Visibility = p.Visibility
+	exists.Title = p.Title
+	exists.Description = p.Description
+	exists.Filename = p.Filename
+	exists.Language = p.Language
+	exists.TitleInferred = p.TitleInferred
+	exists.LanguageInferred = p.LanguageInferred
+	exists.UpdatedAt = now
+	exists.Version++
+	*p = exists
+
+	r.Cache.Set(ctx, p.Slug, p, r.cacheTTL)
+	r.invalidateQuotaUsage(ctx, p.Creator)
 	return nil
 }
 
-func (r *PasteRepository) IncrementViewCount(slug string) error {
-	if _, err := r.GetPasteBySlug(slug); err != nil {
+// RefreshPaste перечитывает пасту из БД в обход кэша и обновляет кэш.
+func (r *PasteRepository) RefreshPaste(ctx context.Context, slug string) (*model.Paste, error) {
+	r.Cache.Invalidate(ctx, slug)
+	return r.GetPasteBySlug(ctx, slug)
+}
+
+func (r *PasteRepository) IncrementViewCount(ctx context.Context, slug string) (err error) {
+	ctx, span := telemetry.Start(ctx, tracerName, "PasteRepository.IncrementViewCount",
+		attribute.String("paste.slug", slug),
+	)
+	defer func() { telemetry.End(span, err) }()
+
+	if _, err := r.GetPasteBySlug(ctx, slug); err != nil {
 		return err
 	}
 
 	now := time.Now()
 
-	if err := r.DB.Model(&model.Paste{}).Where("slug = ?", slug).
-		Updates(map[string]interface{}{
+	// UpdateColumns, а не Updates: просмотр не изменение, updated_at и Last-Modified не трогаем
+	if err := r.DB.WithContext(ctx).Model(&model.Paste{}).Where("slug = ?", slug).
+		UpdateColumns(map[string]interface{}{
 			"view_count":  gorm.Expr("view_count + ?", 1),
 			"last_viewed": now,
 		}).Error; err != nil {
@@ -120,12 +204,12 @@ func (r *PasteRepository) IncrementViewCount(slug string) error {
 	}
 
 	var paste model.Paste
-	if err := r.DB.Where("slug = ?", slug).First(&paste).Error; err == nil {
-		r.Cache.Set(slug, &paste, r.cacheTTL)
+	if err := r.DB.WithContext(ctx).Where("slug = ?", slug).First(&paste).Error; err == nil {
+		r.Cache.Set(ctx, slug, &paste, r.cacheTTL)
 		return nil
 	}
 
-	if cached, ok := r.Cache.Get(slug); ok {
+	if cached, ok := r.Cache.Get(ctx, slug); ok {
 		if paste, valid := cached.(*model.Paste); valid {
 			paste.ViewCount++
 			if paste.LastViewed == nil {
@@ -133,31 +217,48 @@ func (r *PasteRepository) IncrementViewCount(slug string) error {
 			} else {
 				*paste.LastViewed = now
 			}
-			r.Cache.Set(slug, paste, r.cacheTTL)
+			r.Cache.Set(ctx, slug, paste, r.cacheTTL)
 		}
 	}
 
 	return nil
 }
 
-func (r *PasteRepository) GetTopPastes(limit int) ([]model.Paste, error) {
-	var pastes []model.Paste
-	if err := r.DB.Where("expires IS NULL OR expires > ?", time.Now()).
-		Order("view_count DESC").
-		Limit(limit).
-		Find(&pastes).Error; err != nil {
-		return nil, err
+// GetEditTokenHash читает хэш токена из БД в обход кэша, чтобы ротированный
+// токен переставал работать сразу на всех репликах.
+func (r *PasteRepository) GetEditTokenHash(ctx context.Context, slug string) (_ string, err error) {
+	ctx, span := telemetry.Start(ctx, tracerName, "PasteRepository.GetEditTokenHash",
+		attribute.String("paste.slug", slug),
+	)
+	defer func() { telemetry.End(span, err) }()
+
+	var paste model.Paste
+	if err := r.DB.WithContext(ctx).Select("edit_token").Where("slug = ?", slug).First(&paste).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return "", ErrPasteNotFound
+		}
+		return "", err
 	}
-	return pastes, nil
+	return paste.EditToken, nil
 }
 
-func (r *PasteRepository) GetRecentPastes(limit int) ([]model.Paste, error) {
-	var pastes []model.Paste
-	if err := r.DB.Where("expires IS NULL OR expires > ?", time.Now()).
-		Order("created_at DESC").
-		Limit(limit).
-		Find(&pastes).Error; err != nil {
-		return nil, err
+// SetEditTokenHash заменяет хэш токена, только если в БД все еще oldHash.
+func (r *PasteRepository) SetEditTokenHash(ctx context.Context, slug, newHash, oldHash string) (err error) {
+	ctx, span := telemetry.Start(ctx, tracerName, "PasteRepository.SetEditTokenHash",
+		attribute.String("paste.slug", slug),
+	)
+	defer func() { telemetry.End(span, err) }()
+
+	result := r.DB.WithContext(ctx).Model(&model.Paste{}).
+		Where("slug = ? AND edit_token = ?", slug, oldHash).
+		Update("edit_token", newHash)
+	if result.Error != nil {
+		return result.Error
 	}
-	return pastes, nil
+	r.Cache.Invalidate(ctx, slug)
+
+	if result.RowsAffected == 0 {
+		return ErrEditTokenChanged
+	}
+	return nil
 }
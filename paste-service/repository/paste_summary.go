@@ -0,0 +1,96 @@
+package repository
+
+import (
+	"context"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"paste-service/internal/model"
+	"paste-service/internal/telemetry"
+
+	"go.opentelemetry.io/otel/attribute"
+)
+
+// PreviewLength - сколько символов содержимого попадает в превью листинга.
+const PreviewLength = 200
+
+// summaryColumns - что листинг читает всегда. Содержимое целиком не читается:
+// превью режет left(), размер берется из заголовка TOAST без распаковки, а строки
+// считаются в БД, чтобы не гонять мегабайты по сети.
+var summaryColumns = []string{
+	"id", "slug", "title", "language", "tags", "view_count", "created_at",
+	"left(content, " + strconv.Itoa(PreviewLength+1) + ") AS preview",
+	"octet_length(content) AS size",
+	"length(content) - length(replace(content, chr(10), '')) + CASE WHEN right(content, 1) = chr(10) THEN 0 ELSE 1 END AS line_count",
+}
+
+// SummaryFields - дополнительные поля, которые листинг отдает по запросу.
+var SummaryFields = []string{"content", "description", "filename", "updated_at", "last_viewed", "expires", "version"}
+
+// PasteSummary - паста в листинге. Дополнительные поля заполнены, только если их запросили.
+type PasteSummary struct {
+	ID        string
+	Slug      string
+	Title     string
+	Language  string
+	Tags      []string
+	ViewCount int
+	CreatedAt time.Time
+	// PreviewLength+1 символ: по лишнему видно, что превью обрезано
+	Preview   string
+	Size      int64
+	LineCount int
+
+	Content     *string
+	Description *string
+	Filename    *string
+	UpdatedAt   *time.Time
+	LastViewed  *time.Time
+	Expires     *time.Time
+	Version     *int64
+}
+
+func (r *PasteRepository) GetTopPastes(ctx context.Context, limit int, fields []string) (_ []PasteSummary, err error) {
+	ctx, span := telemetry.Start(ctx, tracerName, "PasteRepository.GetTopPastes",
+		attribute.Int("query.limit", limit),
+		attribute.StringSlice("query.fields", fields),
+	)
+	defer func() { telemetry.End(span, err) }()
+
+	return r.listSummaries(ctx, "view_count DESC", limit, fields)
+}
+
+func (r *PasteRepository) GetRecentPastes(ctx context.Context, limit int, fields []string) (_ []PasteSummary, err error) {
+	ctx, span := telemetry.Start(ctx, tracerName, "PasteRepository.GetRecentPastes",
+		attribute.Int("query.limit", limit),
+		attribute.StringSlice("query.fields", fields),
+	)
+	defer func() { telemetry.End(span, err) }()
+
+	return r.listSummaries(ctx, "created_at DESC", limit, fields)
+}
+
+// listSummaries читает публичные неистекшие пасты. Поля не из SummaryFields
+// пропускаются: имена попадают в SELECT как есть.
+func (r *PasteRepository) listSummaries(ctx context.Context, order string, limit int, fields []string) ([]PasteSummary, error) {
+	columns := append([]string{}, summaryColumns...)
+	for _, field := range fields {
+		if slices.Contains(SummaryFields, field) {
+			columns = append(columns, field)
+		}
+	}
+
+	var summaries []PasteSummary
+	if err := r.DB.WithContext(ctx).Model(&model.Paste{}).
+		Select(strings.Join(columns, ", ")).
+		Where("expires IS NULL OR expires > ?", time.Now()).
+		Where("hidden = ? AND visibility = ?", false, model.VisibilityPublic).
+		Order(order).
+		Limit(limit).
+		Scan(&summaries).Error; err != nil {
+		return nil, err
+	}
+	return summaries, nil
+}
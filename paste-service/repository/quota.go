@@ -0,0 +1,86 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"paste-service/internal/model"
+	"paste-service/internal/telemetry"
+
+	"gorm.io/gorm"
+)
+
+// QuotaUsage - текущее потребление субъекта квоты.
+type QuotaUsage struct {
+	PastesLastDay int64 `json:"pastes_last_day"`
+	StoredBytes   int64 `json:"stored_bytes"`
+}
+
+// QuotaCheck решает по свежему подсчету, можно ли создать пасту. Ошибка отменяет вставку.
+type QuotaCheck func(usage *QuotaUsage) error
+
+func quotaCacheKey(creator string) string {
+	return "quota:" + creator
+}
+
+// GetQuotaUsage считает потребление по таблице паст и держит результат в кэше.
+// Кэш сбрасывается при создании и изменении пасты, удаление и истечение
+// подхватываются по TTL.
+func (r *PasteRepository) GetQuotaUsage(ctx context.Context, creator string, ttl time.Duration) (_ *QuotaUsage, err error) {
+	ctx, span := telemetry.Start(ctx, tracerName, "PasteRepository.GetQuotaUsage")
+	defer func() { telemetry.End(span, err) }()
+
+	var usage QuotaUsage
+	if r.Cache.GetTyped(ctx, quotaCacheKey(creator), &usage) {
+		return &usage, nil
+	}
+
+	counted, err := countQuotaUsage(r.DB.WithContext(ctx), creator)
+	if err != nil {
+		return nil, err
+	}
+
+	r.Cache.Set(ctx, quotaCacheKey(creator), counted, ttl)
+	return counted, nil
+}
+
+func countQuotaUsage(db *gorm.DB, creator string) (*QuotaUsage, error) {
+	var usage QuotaUsage
+	now := time.Now()
+	if err := db.Model(&model.Paste{}).
+		Select(
+			"COUNT(*) FILTER (WHERE created_at > ?) AS pastes_last_day, "+
+				"COALESCE(SUM(octet_length(content)) FILTER (WHERE expires IS NULL OR expires > ?), 0) AS stored_bytes",
+			now.Add(-24*time.Hour), now,
+		).
+		Where("creator = ?", creator).
+		Scan(&usage).Error; err != nil {
+		return nil, err
+	}
+	return &usage, nil
+}
+
+// createWithinQuota вставляет пасту, если check пропускает свежий подсчет. Вставки одного
+// субъекта идут по очереди под advisory-блокировкой транзакции: иначе параллельные
+// запросы видят одно и то же потребление и вместе проходят лимит.
+func createWithinQuota(db *gorm.DB, p *model.Paste, check QuotaCheck) error {
+	return db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Exec("SELECT pg_advisory_xact_lock(hashtext(?))", quotaCacheKey(p.Creator)).Error; err != nil {
+			return err
+		}
+		usage, err := countQuotaUsage(tx, p.Creator)
+		if err != nil {
+			return err
+		}
+		if err := check(usage); err != nil {
+			return err
+		}
+		return tx.Create(p).Error
+	})
+}
+
+func (r *PasteRepository) invalidateQuotaUsage(ctx context.Context, creator string) {
+	if creator != "" {
+		r.Cache.Invalidate(ctx, quotaCacheKey(creator))
+	}
+}
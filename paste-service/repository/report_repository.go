@@ -0,0 +1,128 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"paste-service/internal/model"
+	"paste-service/internal/telemetry"
+
+	"go.opentelemetry.io/otel/attribute"
+	"gorm.io/gorm"
+)
+
+var (
+	ErrReportNotFound  = errors.New("жалоба не найдена")
+	ErrAlreadyReported = errors.New("жалоба на эту пасту уже отправлена")
+)
+
+type ReportRepository struct {
+	DB *gorm.DB
+}
+
+func NewReportRepository(db *gorm.DB) *ReportRepository {
+	return &ReportRepository{DB: db}
+}
+
+func (r *ReportRepository) Create(ctx context.Context, report *model.Report) (err error) {
+	ctx, span := telemetry.Start(ctx, tracerName, "ReportRepository.Create",
+		attribute.String("paste.slug", report.PasteSlug),
+		attribute.String("report.reason", report.Reason),
+	)
+	defer func() { telemetry.End(span, err) }()
+
+	if err := report.Validate(); err != nil {
+		return err
+	}
+
+	if err := r.DB.WithContext(ctx).Create(report).Error; err != nil {
+		if errors.Is(err, gorm.ErrDuplicatedKey) {
+			return ErrAlreadyReported
+		}
+		return err
+	}
+	return nil
+}
+
+func (r *ReportRepository) GetByID(ctx context.Context, id string) (_ *model.Report, err error) {
+	ctx, span := telemetry.Start(ctx, tracerName, "ReportRepository.GetByID")
+	defer func() { telemetry.End(span, err) }()
+
+	var report model.Report
+	if err := r.DB.WithContext(ctx).Where("id = ?", id).First(&report).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrReportNotFound
+		}
+		return nil, err
+	}
+	return &report, nil
+}
+
+// CountByReporterSince - сколько жалоб клиент отправил с момента since, для ограничения частоты.
+func (r *ReportRepository) CountByReporterSince(ctx context.Context, reporterHash string, since time.Time) (_ int64, err error) {
+	ctx, span := telemetry.Start(ctx, tracerName, "ReportRepository.CountByReporterSince")
+	defer func() { telemetry.End(span, err) }()
+
+	var count int64
+	err = r.DB.WithContext(ctx).Model(&model.Report{}).
+		Where("reporter_hash = ? AND created_at > ?", reporterHash, since).
+		Count(&count).Error
+	return count, err
+}
+
+// CountOpenForPaste считает открытые жалобы на пасту. Открытые жалобы от одного клиента
+// уникальны по индексу, поэтому это число разных жалобщиков.
+func (r *ReportRepository) CountOpenForPaste(ctx context.Context, slug string) (_ int64, err error) {
+	ctx, span := telemetry.Start(ctx, tracerName, "ReportRepository.CountOpenForPaste",
+		attribute.String("paste.slug", slug),
+	)
+	defer func() { telemetry.End(span, err) }()
+
+	var count int64
+	err = r.DB.WithContext(ctx).Model(&model.Report{}).
+		Where("paste_slug = ? AND status = ?", slug, model.ReportStatusOpen).
+		Count(&count).Error
+	return count, err
+}
+
+func (r *ReportRepository) List(ctx context.Context, status, slug string, limit, offset int) (_ []model.Report, err error) {
+	ctx, span := telemetry.Start(ctx, tracerName, "ReportRepository.List",
+		attribute.String("report.status", status),
+	)
+	defer func() { telemetry.End(span, err) }()
+
+	query := r.DB.WithContext(ctx).Model(&model.Report{})
+	if status != "" {
+		query = query.Where("status = ?", status)
+	}
+	if slug != "" {
+		query = query.Where("paste_slug = ?", slug)
+	}
+
+	var reports []model.Report
+	if err := query.Order("created_at ASC").Limit(limit).Offset(offset).Find(&reports).Error; err != nil {
+		return nil, err
+	}
+	return reports, nil
+}
+
+// ResolveOpenForPaste закрывает все открытые жалобы на пасту одним решением.
+func (r *ReportRepository) ResolveOpenForPaste(ctx context.Context, slug, status, resolution, resolvedBy string) (_ int64, err error) {
+	ctx, span := telemetry.Start(ctx, tracerName, "ReportRepository.ResolveOpenForPaste",
+		attribute.String("paste.slug", slug),
+		attribute.String("report.status", status),
+	)
+	defer func() { telemetry.End(span, err) }()
+
+	now := time.Now()
+	result := r.DB.WithContext(ctx).Model(&model.Report{}).
+		Where("paste_slug = ? AND status = ?", slug, model.ReportStatusOpen).
+		Updates(map[string]interface{}{
+			"status":      status,
+			"resolution":  resolution,
+			"resolved_by": resolvedBy,
+			"resolved_at": now,
+		})
+	return result.RowsAffected, result.Error
+}
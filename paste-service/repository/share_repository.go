@@ -0,0 +1,117 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"paste-service/internal/model"
+	"paste-service/internal/telemetry"
+
+	"go.opentelemetry.io/otel/attribute"
+	"gorm.io/gorm"
+)
+
+var (
+	ErrShareLinkNotFound  = errors.New("ссылка не найдена")
+	ErrShareLinkExhausted = errors.New("ссылка отозвана, истекла или исчерпала лимит просмотров")
+)
+
+type ShareRepository struct {
+	DB *gorm.DB
+}
+
+func NewShareRepository(db *gorm.DB) *ShareRepository {
+	return &ShareRepository{DB: db}
+}
+
+func (r *ShareRepository) Create(ctx context.Context, link *model.ShareLink) (err error) {
+	ctx, span := telemetry.Start(ctx, tracerName, "ShareRepository.Create",
+		attribute.String("paste.slug", link.PasteSlug),
+	)
+	defer func() { telemetry.End(span, err) }()
+
+	return r.DB.WithContext(ctx).Create(link).Error
+}
+
+func (r *ShareRepository) ListForPaste(ctx context.Context, slug string) (_ []model.ShareLink, err error) {
+	ctx, span := telemetry.Start(ctx, tracerName, "ShareRepository.ListForPaste",
+		attribute.String("paste.slug", slug),
+	)
+	defer func() { telemetry.End(span, err) }()
+
+	var links []model.ShareLink
+	if err := r.DB.WithContext(ctx).Where("paste_slug = ?", slug).
+		Order("created_at DESC").
+		Find(&links).Error; err != nil {
+		return nil, err
+	}
+	return links, nil
+}
+
+func (r *ShareRepository) Revoke(ctx context.Context, slug, id string) (err error) {
+	ctx, span := telemetry.Start(ctx, tracerName, "ShareRepository.Revoke",
+		attribute.String("paste.slug", slug),
+	)
+	defer func() { telemetry.End(span, err) }()
+
+	now := time.Now()
+	result := r.DB.WithContext(ctx).Model(&model.ShareLink{}).
+		Where("id = ? AND paste_slug = ?", id, slug).
+		Updates(map[string]interface{}{
+			"revoked":    true,
+			"revoked_at": now,
+		})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return ErrShareLinkNotFound
+	}
+	return nil
+}
+
+// Redeem засчитывает просмотр по ссылке одним условным UPDATE, чтобы
+// параллельные запросы не превысили лимит.
+func (r *ShareRepository) Redeem(ctx context.Context, slug, id string) (err error) {
+	ctx, span := telemetry.Start(ctx, tracerName, "ShareRepository.Redeem",
+		attribute.String("paste.slug", slug),
+	)
+	defer func() { telemetry.End(span, err) }()
+
+	result := r.DB.WithContext(ctx).Model(&model.ShareLink{}).
+		Where("id = ? AND paste_slug = ? AND revoked = ? AND expires_at > ?", id, slug, false, time.Now()).
+		Where("max_views = 0 OR views < max_views").
+		Update("views", gorm.Expr("views + 1"))
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return ErrShareLinkExhausted
+	}
+	return nil
+}
+
+// Check проверяет, что ссылка не отозвана и не истекла, не списывая просмотр.
+// С opened ссылку должны были открыть хотя бы раз.
+func (r *ShareRepository) Check(ctx context.Context, slug, id string, opened bool) (err error) {
+	ctx, span := telemetry.Start(ctx, tracerName, "ShareRepository.Check",
+		attribute.String("paste.slug", slug),
+	)
+	defer func() { telemetry.End(span, err) }()
+
+	query := r.DB.WithContext(ctx).Model(&model.ShareLink{}).
+		Where("id = ? AND paste_slug = ? AND revoked = ? AND expires_at > ?", id, slug, false, time.Now())
+	if opened {
+		query = query.Where("views > 0")
+	}
+
+	var count int64
+	if err := query.Count(&count).Error; err != nil {
+		return err
+	}
+	if count == 0 {
+		return ErrShareLinkExhausted
+	}
+	return nil
+}
@@ -0,0 +1,104 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"paste-service/internal/model"
+	"paste-service/internal/telemetry"
+
+	"gorm.io/gorm"
+)
+
+var (
+	ErrUserNotFound    = errors.New("пользователь не найден")
+	ErrUsernameTaken   = errors.New("имя пользователя занято")
+	ErrSessionNotFound = errors.New("сессия не найдена")
+)
+
+type UserRepository struct {
+	DB *gorm.DB
+}
+
+func NewUserRepository(db *gorm.DB) *UserRepository {
+	return &UserRepository{DB: db}
+}
+
+func (r *UserRepository) Create(ctx context.Context, user *model.User) (err error) {
+	ctx, span := telemetry.Start(ctx, tracerName, "UserRepository.Create")
+	defer func() { telemetry.End(span, err) }()
+
+	if err := r.DB.WithContext(ctx).Create(user).Error; err != nil {
+		if errors.Is(err, gorm.ErrDuplicatedKey) {
+			return ErrUsernameTaken
+		}
+		return err
+	}
+	return nil
+}
+
+func (r *UserRepository) GetByUsername(ctx context.Context, username string) (_ *model.User, err error) {
+	ctx, span := telemetry.Start(ctx, tracerName, "UserRepository.GetByUsername")
+	defer func() { telemetry.End(span, err) }()
+
+	var user model.User
+	if err := r.DB.WithContext(ctx).Where("username = ?", username).First(&user).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrUserNotFound
+		}
+		return nil, err
+	}
+	return &user, nil
+}
+
+func (r *UserRepository) GetByID(ctx context.Context, id string) (_ *model.User, err error) {
+	ctx, span := telemetry.Start(ctx, tracerName, "UserRepository.GetByID")
+	defer func() { telemetry.End(span, err) }()
+
+	var user model.User
+	if err := r.DB.WithContext(ctx).Where("id = ?", id).First(&user).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrUserNotFound
+		}
+		return nil, err
+	}
+	return &user, nil
+}
+
+func (r *UserRepository) CreateSession(ctx context.Context, session *model.Session) (err error) {
+	ctx, span := telemetry.Start(ctx, tracerName, "UserRepository.CreateSession")
+	defer func() { telemetry.End(span, err) }()
+
+	return r.DB.WithContext(ctx).Create(session).Error
+}
+
+// GetSession отдает только действующую сессию, истекшая считается отсутствующей.
+func (r *UserRepository) GetSession(ctx context.Context, id string) (_ *model.Session, err error) {
+	ctx, span := telemetry.Start(ctx, tracerName, "UserRepository.GetSession")
+	defer func() { telemetry.End(span, err) }()
+
+	var session model.Session
+	if err := r.DB.WithContext(ctx).Where("id = ? AND expires_at > ?", id, time.Now()).First(&session).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrSessionNotFound
+		}
+		return nil, err
+	}
+	return &session, nil
+}
+
+func (r *UserRepository) DeleteSession(ctx context.Context, id string) (err error) {
+	ctx, span := telemetry.Start(ctx, tracerName, "UserRepository.DeleteSession")
+	defer func() { telemetry.End(span, err) }()
+
+	return r.DB.WithContext(ctx).Where("id = ?", id).Delete(&model.Session{}).Error
+}
+
+// DeleteExpiredSessions чистит истекшие сессии пользователя, вызывается при входе.
+func (r *UserRepository) DeleteExpiredSessions(ctx context.Context, userID string) (err error) {
+	ctx, span := telemetry.Start(ctx, tracerName, "UserRepository.DeleteExpiredSessions")
+	defer func() { telemetry.End(span, err) }()
+
+	return r.DB.WithContext(ctx).Where("user_id = ? AND expires_at <= ?", userID, time.Now()).Delete(&model.Session{}).Error
+}